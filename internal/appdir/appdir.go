@@ -0,0 +1,25 @@
+package appdir
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Dir returns the directory holding ClaudeShelf's own configuration and data
+// (trash, history). It is ~/.config/claudeshelf on Linux and the platform
+// equivalent elsewhere.
+func Dir() string {
+	if base, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(base, "claudeshelf")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".claudeshelf")
+}
+
+// CacheDir returns the directory for disposable ClaudeShelf caches.
+func CacheDir() string {
+	if base, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(base, "claudeshelf")
+	}
+	return filepath.Join(Dir(), "cache")
+}
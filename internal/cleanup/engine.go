@@ -0,0 +1,199 @@
+package cleanup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+)
+
+// maxContentSize bounds the files whose content is inspected by content
+// conditions; larger files never satisfy them.
+const maxContentSize = 1 << 20
+
+// Engine evaluates compiled cleanup rules.
+type Engine struct {
+	rules      []compiledRule
+	duplicates map[string]models.FileEntry // copy ID → original
+}
+
+// NeedsDuplicates reports whether any rule tests for duplicates, in which
+// case SetDuplicates must be called before Evaluate.
+func (e *Engine) NeedsDuplicates() bool {
+	for _, r := range e.rules {
+		if r.When.Duplicate {
+			return true
+		}
+	}
+	return false
+}
+
+// SetDuplicates provides the exact duplicate groups used by the duplicate
+// condition. Files[0] of each group is the original and never matches.
+func (e *Engine) SetDuplicates(groups []models.DuplicateGroup) {
+	e.duplicates = make(map[string]models.FileEntry)
+	for _, g := range groups {
+		for _, f := range g.Files[1:] {
+			e.duplicates[f.ID] = g.Files[0]
+		}
+	}
+}
+
+// Evaluate runs the rules against a file in order. It returns the cleanup
+// item for the first flagging rule that matches, or false if no rule flags
+// the file or a keep rule matches first.
+func (e *Engine) Evaluate(f models.FileEntry, now time.Time) (models.CleanupItem, bool) {
+	fc := fileCheck{entry: f, age: int(now.Sub(f.ModTime).Hours() / 24)}
+	if original, ok := e.duplicates[f.ID]; ok {
+		fc.original = &original
+	}
+	for _, r := range e.rules {
+		if !r.matches(&fc) {
+			continue
+		}
+		if r.Action == models.CleanupKeep {
+			return models.CleanupItem{}, false
+		}
+		item := models.CleanupItem{
+			FileEntry:   f,
+			Reason:      r.Reason,
+			ReasonLabel: fc.label(r.Label),
+			Rule:        r.Name,
+		}
+		if r.When.OlderThanDays != nil {
+			item.DaysSince = fc.age
+		}
+		return item, true
+	}
+	return models.CleanupItem{}, false
+}
+
+// fileCheck carries a file through rule evaluation, reading its content at
+// most once and only if a rule needs it.
+type fileCheck struct {
+	entry   models.FileEntry
+	age     int
+	read    bool
+	content []byte
+	ok      bool // content was read
+
+	original *models.FileEntry // set if the file is a redundant copy
+}
+
+func (fc *fileCheck) data() ([]byte, bool) {
+	if !fc.read {
+		fc.read = true
+		if fc.entry.Size <= maxContentSize {
+			if data, err := os.ReadFile(fc.entry.Path); err == nil {
+				fc.content, fc.ok = data, true
+			}
+		}
+	}
+	return fc.content, fc.ok
+}
+
+func (r *compiledRule) matches(fc *fileCheck) bool {
+	f := fc.entry
+	if len(r.Match.Categories) > 0 && !slices.Contains(r.Match.Categories, f.Category) {
+		return false
+	}
+	if len(r.Match.Scopes) > 0 && !slices.Contains(r.Match.Scopes, f.Scope) {
+		return false
+	}
+	if len(r.paths) > 0 {
+		path := filepath.ToSlash(f.Path)
+		matched := false
+		for _, re := range r.paths {
+			if re.MatchString(path) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	w := r.When
+	if w.OlderThanDays != nil && fc.age < *w.OlderThanDays {
+		return false
+	}
+	if w.MinSize != nil && f.Size < *w.MinSize {
+		return false
+	}
+	if w.MaxSize != nil && f.Size > *w.MaxSize {
+		return false
+	}
+	if w.Empty {
+		data, ok := fc.data()
+		if !ok || emptyContentLabel(data) == "" {
+			return false
+		}
+	}
+	if w.Duplicate && fc.original == nil {
+		return false
+	}
+	if r.content != nil {
+		data, ok := fc.data()
+		if !ok || !r.content.Match(data) {
+			return false
+		}
+	}
+	return true
+}
+
+// label expands the placeholders of a rule label for the file.
+func (fc *fileCheck) label(tmpl string) string {
+	if !strings.Contains(tmpl, "{") {
+		return tmpl
+	}
+	content := ""
+	if strings.Contains(tmpl, "{content}") {
+		data, _ := fc.data()
+		content = emptyContentLabel(data)
+	}
+	original := ""
+	if fc.original != nil {
+		original = fc.original.RelPath
+	}
+	return strings.NewReplacer(
+		"{age}", strconv.Itoa(fc.age),
+		"{size}", formatSize(fc.entry.Size),
+		"{content}", content,
+		"{original}", original,
+	).Replace(tmpl)
+}
+
+// emptyContentLabel describes content that is blank, [], {} or null, and
+// returns "" for anything else.
+func emptyContentLabel(data []byte) string {
+	switch strings.TrimSpace(string(data)) {
+	case "":
+		if len(data) == 0 {
+			return "Empty content"
+		}
+		return "Blank file (whitespace only)"
+	case "[]":
+		return "Empty array ([])"
+	case "{}":
+		return "Empty object ({})"
+	case "null":
+		return "Null content"
+	}
+	return ""
+}
+
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
@@ -0,0 +1,188 @@
+// Package cleanup decides which files to suggest for cleanup, using an
+// ordered list of rules loaded from a JSON file.
+package cleanup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/fsutil"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+)
+
+// ruleFile is the on-disk format of the rules file.
+type ruleFile struct {
+	Rules []models.CleanupRule `json:"rules"`
+}
+
+// Defaults returns the built-in rules, used when no rules file exists:
+// empty files, files with empty content, redundant copies of other files,
+// and files untouched for 30 days.
+func Defaults() []models.CleanupRule {
+	zero := int64(0)
+	thirty := 30
+	return []models.CleanupRule{
+		{
+			Name:   "empty-file",
+			When:   models.CleanupConditions{MaxSize: &zero},
+			Reason: models.ReasonEmptyFile,
+			Label:  "Empty file (0 bytes)",
+		},
+		{
+			Name:   "empty-content",
+			When:   models.CleanupConditions{Empty: true},
+			Reason: models.ReasonEmptyContent,
+			Label:  "{content}",
+		},
+		{
+			Name:   "duplicate",
+			When:   models.CleanupConditions{Duplicate: true},
+			Reason: models.ReasonDuplicate,
+			Label:  "Duplicate of {original}",
+		},
+		{
+			Name:   "stale",
+			When:   models.CleanupConditions{OlderThanDays: &thirty},
+			Reason: models.ReasonStale,
+			Label:  "Not modified in {age} days",
+		},
+	}
+}
+
+// Load reads the rules file at path, falling back to Defaults if it does
+// not exist.
+func Load(path string) (models.CleanupRuleSet, error) {
+	set := models.CleanupRuleSet{Path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		set.Default = true
+		set.Rules = Defaults()
+		return set, nil
+	}
+	if err != nil {
+		return set, err
+	}
+
+	var f ruleFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return set, fmt.Errorf("%s: %w", path, err)
+	}
+	if _, err := Compile(f.Rules); err != nil {
+		return set, fmt.Errorf("%s: %w", path, err)
+	}
+	set.Rules = f.Rules
+	return set, nil
+}
+
+// Save validates rules and writes them to path.
+func Save(path string, rules []models.CleanupRule) error {
+	if _, err := Compile(rules); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(ruleFile{Rules: rules}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(path, append(data, '\n'), 0600)
+}
+
+// compiledRule is a validated rule with its patterns compiled.
+type compiledRule struct {
+	models.CleanupRule
+	paths   []*regexp.Regexp
+	content *regexp.Regexp
+}
+
+// Compile validates rules and prepares them for evaluation.
+func Compile(rules []models.CleanupRule) (*Engine, error) {
+	e := &Engine{}
+	names := make(map[string]bool)
+	for i, r := range rules {
+		where := fmt.Sprintf("rule %d", i+1)
+		if r.Name != "" {
+			where += fmt.Sprintf(" (%s)", r.Name)
+		}
+		switch {
+		case r.Name == "":
+			return nil, fmt.Errorf("%s: missing name", where)
+		case names[r.Name]:
+			return nil, fmt.Errorf("%s: duplicate name", where)
+		case r.Action != "" && r.Action != models.CleanupFlag && r.Action != models.CleanupKeep:
+			return nil, fmt.Errorf("%s: action must be %q or %q", where, models.CleanupFlag, models.CleanupKeep)
+		case r.Action != models.CleanupKeep && r.Reason == "":
+			return nil, fmt.Errorf("%s: missing reason", where)
+		}
+		names[r.Name] = true
+
+		c := compiledRule{CleanupRule: r}
+		for _, p := range r.Match.Paths {
+			re, err := globRegexp(p)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid path pattern %q: %w", where, p, err)
+			}
+			c.paths = append(c.paths, re)
+		}
+		if r.When.ContentRegex != "" {
+			re, err := regexp.Compile(r.When.ContentRegex)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid contentRegex: %w", where, err)
+			}
+			c.content = re
+		}
+		e.rules = append(e.rules, c)
+	}
+	return e, nil
+}
+
+// globRegexp converts a path glob into an anchored, case-insensitive regexp.
+// Absolute and "~/" patterns match the whole path; other patterns match its
+// trailing components, so "CLAUDE.md" matches the file name and
+// "debug/*.txt" a file in any directory named debug.
+func globRegexp(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, errors.New("empty pattern")
+	}
+	p := filepath.ToSlash(pattern)
+	if strings.HasPrefix(p, "~/") {
+		home, _ := os.UserHomeDir()
+		p = filepath.ToSlash(home) + p[1:]
+	}
+
+	var b strings.Builder
+	b.WriteString("(?i)")
+	if strings.HasPrefix(p, "/") || filepath.IsAbs(pattern) {
+		b.WriteString("^")
+	} else {
+		b.WriteString("(^|/)")
+	}
+	for i := 0; i < len(p); i++ {
+		switch c := p[i]; c {
+		case '*':
+			if i+1 < len(p) && p[i+1] == '*' {
+				i++
+				if i+1 < len(p) && p[i+1] == '/' {
+					i++
+					b.WriteString("(.*/)?") // "**/" also matches no directory
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
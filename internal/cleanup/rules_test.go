@@ -0,0 +1,39 @@
+package cleanup
+
+import (
+	"os"
+	"testing"
+)
+
+func TestGlobRegexp(t *testing.T) {
+	home, _ := os.UserHomeDir()
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"CLAUDE.md", "/home/u/app/CLAUDE.md", true},
+		{"claude.md", "/home/u/app/CLAUDE.md", true},
+		{"CLAUDE.md", "/home/u/app/CLAUDE.md.bak", false},
+		{"*.log", "/home/u/.claude/debug/a.log", true},
+		{"debug/*.txt", "/home/u/.claude/debug/a.txt", true},
+		{"debug/*.txt", "/home/u/.claude/debug/sub/a.txt", false},
+		{"debug/*.txt", "/home/u/.claude/nodebug/a.txt", false},
+		{".claude/todos/*.json", "/home/u/.claude/todos/t.json", true},
+		{"**/debug/*.txt", "/home/u/.claude/debug/a.txt", true},
+		{"debug/**", "/home/u/.claude/debug/sub/a.txt", true},
+		{"/home/u/.claude/debug/*", "/home/u/.claude/debug/a.txt", true},
+		{"/.claude/debug/*", "/home/u/.claude/debug/a.txt", false},
+		{"~/.claude/**/*.txt", home + "/.claude/debug/a.txt", true},
+		{"~/.claude/**/*.txt", "/elsewhere/.claude/debug/a.txt", false},
+	}
+	for _, tt := range tests {
+		re, err := globRegexp(tt.pattern)
+		if err != nil {
+			t.Fatalf("globRegexp(%q): %v", tt.pattern, err)
+		}
+		if got := re.MatchString(tt.path); got != tt.want {
+			t.Errorf("%q matching %q = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Op is the kind of change a diff line represents.
+type Op string
+
+const (
+	OpEqual  Op = "equal"
+	OpInsert Op = "insert"
+	OpDelete Op = "delete"
+)
+
+// Line is a single line of a line-level diff. OldLine and NewLine are 1-based
+// and zero when the line does not exist on that side.
+type Line struct {
+	Op      Op     `json:"op"`
+	Text    string `json:"text"`
+	OldLine int    `json:"oldLine,omitempty"`
+	NewLine int    `json:"newLine,omitempty"`
+}
+
+// Hunk is a group of changed lines with surrounding context.
+type Hunk struct {
+	OldStart int    `json:"oldStart"`
+	OldLines int    `json:"oldLines"`
+	NewStart int    `json:"newStart"`
+	NewLines int    `json:"newLines"`
+	Lines    []Line `json:"lines"`
+}
+
+// SplitLines splits text into lines without their trailing newlines.
+// An empty string has no lines.
+func SplitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.Split(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Lines computes a line-level diff turning a into b.
+func Lines(a, b string) []Line {
+	return diffLines(SplitLines(a), SplitLines(b))
+}
+
+func diffLines(a, b []string) []Line {
+	out := make([]Line, 0, len(a)+len(b))
+	oi, ni := 0, 0
+	for _, m := range matches(a, b) {
+		for ; oi < m[0]; oi++ {
+			out = append(out, Line{Op: OpDelete, Text: a[oi], OldLine: oi + 1})
+		}
+		for ; ni < m[1]; ni++ {
+			out = append(out, Line{Op: OpInsert, Text: b[ni], NewLine: ni + 1})
+		}
+		out = append(out, Line{Op: OpEqual, Text: a[oi], OldLine: oi + 1, NewLine: ni + 1})
+		oi++
+		ni++
+	}
+	for ; oi < len(a); oi++ {
+		out = append(out, Line{Op: OpDelete, Text: a[oi], OldLine: oi + 1})
+	}
+	for ; ni < len(b); ni++ {
+		out = append(out, Line{Op: OpInsert, Text: b[ni], NewLine: ni + 1})
+	}
+	return out
+}
+
+// Hunks groups a diff into hunks with the given number of context lines.
+// Identical inputs produce no hunks.
+func Hunks(a, b string, context int) []Hunk {
+	lines := Lines(a, b)
+	var hunks []Hunk
+	for i := 0; i < len(lines); {
+		if lines[i].Op == OpEqual {
+			i++
+			continue
+		}
+		// Extend the hunk until we see more than 2*context equal lines in a row.
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(lines) {
+			if lines[end].Op != OpEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(lines) && lines[run].Op == OpEqual {
+				run++
+			}
+			if run == len(lines) || run-end > 2*context {
+				end += min(context, run-end)
+				break
+			}
+			end = run
+		}
+		hunks = append(hunks, newHunk(lines[start:end]))
+		i = end
+	}
+	return hunks
+}
+
+func newHunk(lines []Line) Hunk {
+	h := Hunk{Lines: lines}
+	for _, l := range lines {
+		if l.OldLine > 0 {
+			if h.OldStart == 0 {
+				h.OldStart = l.OldLine
+			}
+			h.OldLines++
+		}
+		if l.NewLine > 0 {
+			if h.NewStart == 0 {
+				h.NewStart = l.NewLine
+			}
+			h.NewLines++
+		}
+	}
+	return h
+}
+
+// Unified renders a unified diff between a and b with three lines of context.
+func Unified(aName, bName, a, b string) string {
+	hunks := Hunks(a, b, 3)
+	if len(hunks) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", aName, bName)
+	for _, h := range hunks {
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", h.OldStart, h.OldLines, h.NewStart, h.NewLines)
+		for _, l := range h.Lines {
+			switch l.Op {
+			case OpEqual:
+				sb.WriteString(" ")
+			case OpInsert:
+				sb.WriteString("+")
+			case OpDelete:
+				sb.WriteString("-")
+			}
+			sb.WriteString(l.Text)
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// matches returns index pairs of a longest common subsequence of a and b.
+// Lines that occur on only one side cannot match and are set aside first; the
+// rest are compared with the linear-space variant of Myers' O(ND) algorithm,
+// so memory stays proportional to the input however much it changed.
+func matches(a, b []string) [][2]int {
+	ids := make(map[string]int)
+	intern := func(lines []string) []int {
+		out := make([]int, len(lines))
+		for i, l := range lines {
+			id, ok := ids[l]
+			if !ok {
+				id = len(ids)
+				ids[l] = id
+			}
+			out[i] = id
+		}
+		return out
+	}
+	ia, ib := intern(a), intern(b)
+
+	inA := make([]bool, len(ids))
+	inB := make([]bool, len(ids))
+	for _, id := range ia {
+		inA[id] = true
+	}
+	for _, id := range ib {
+		inB[id] = true
+	}
+	// Keep only the lines found on both sides, remembering where they were.
+	reduce := func(lines []int, other []bool) (kept, index []int) {
+		for i, id := range lines {
+			if other[id] {
+				kept = append(kept, id)
+				index = append(index, i)
+			}
+		}
+		return kept, index
+	}
+	ra, xa := reduce(ia, inB)
+	rb, xb := reduce(ib, inA)
+	if len(ra) == 0 || len(rb) == 0 {
+		return nil
+	}
+
+	size := len(ra) + len(rb) + 4
+	l := &lcs{a: ra, b: rb, vf: make([]int, size), vb: make([]int, size)}
+	l.compare(0, len(ra), 0, len(rb))
+	for i, p := range l.pairs {
+		l.pairs[i] = [2]int{xa[p[0]], xb[p[1]]}
+	}
+	return l.pairs
+}
+
+// lcs finds a longest common subsequence by splitting the problem at the
+// middle snake of the optimal edit path and recursing on either side.
+type lcs struct {
+	a, b   []int
+	vf, vb []int // furthest reaching paths forwards and backwards, reused
+	pairs  [][2]int
+}
+
+// compare appends, in order, the matched pairs of a[a0:a1] and b[b0:b1].
+func (l *lcs) compare(a0, a1, b0, b1 int) {
+	for a0 < a1 && b0 < b1 && l.a[a0] == l.b[b0] {
+		l.pairs = append(l.pairs, [2]int{a0, b0})
+		a0++
+		b0++
+	}
+	suffix := 0
+	for a0 < a1 && b0 < b1 && l.a[a1-1] == l.b[b1-1] {
+		a1--
+		b1--
+		suffix++
+	}
+	// With both sides non-empty and their ends differing, the edit distance
+	// is at least two, so both halves below are strictly smaller.
+	if a0 < a1 && b0 < b1 {
+		x, y, u, v := l.middleSnake(a0, a1, b0, b1)
+		l.compare(a0, x, b0, y)
+		for ; x < u; x, y = x+1, y+1 {
+			l.pairs = append(l.pairs, [2]int{x, y})
+		}
+		l.compare(u, a1, v, b1)
+	}
+	for i := 0; i < suffix; i++ {
+		l.pairs = append(l.pairs, [2]int{a1 + i, b1 + i})
+	}
+}
+
+// middleSnake runs the forward and backward searches until they overlap and
+// returns the snake, from (x, y) to (u, v), where they met.
+func (l *lcs) middleSnake(a0, a1, b0, b1 int) (x, y, u, v int) {
+	n, m := a1-a0, b1-b0
+	delta := n - m
+	odd := delta%2 != 0
+	maxD := (n + m + 1) / 2
+	// Diagonal k is x-y for the forward search and x-y-delta backwards.
+	off := maxD + 1
+	vf, vb := l.vf, l.vb
+	vf[off+1] = 0
+	vb[off-1] = n
+
+	for d := 0; d <= maxD; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && vf[off+k-1] < vf[off+k+1]) {
+				x = vf[off+k+1]
+			} else {
+				x = vf[off+k-1] + 1
+			}
+			y := x - k
+			sx, sy := x, y
+			for x < n && y < m && l.a[a0+x] == l.b[b0+y] {
+				x++
+				y++
+			}
+			vf[off+k] = x
+			if c := k - delta; odd && c >= -(d-1) && c <= d-1 && vb[off+c] <= x {
+				return a0 + sx, b0 + sy, a0 + x, b0 + y
+			}
+		}
+		for c := -d; c <= d; c += 2 {
+			var x int
+			if c == d || (c != -d && vb[off+c-1] < vb[off+c+1]) {
+				x = vb[off+c-1]
+			} else {
+				x = vb[off+c+1] - 1
+			}
+			k := c + delta
+			y := x - k
+			ex, ey := x, y
+			for x > 0 && y > 0 && l.a[a0+x-1] == l.b[b0+y-1] {
+				x--
+				y--
+			}
+			vb[off+c] = x
+			if !odd && k >= -d && k <= d && vf[off+k] >= x {
+				return a0 + x, b0 + y, a0 + ex, b0 + ey
+			}
+		}
+	}
+	panic("diff: middle snake not found")
+}
@@ -0,0 +1,107 @@
+package diff
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// check fails unless diff turns a into b, and returns its number of equal lines.
+func check(t *testing.T, a, b []string, diff []Line) int {
+	t.Helper()
+	var old, new []string
+	equal := 0
+	for _, l := range diff {
+		switch l.Op {
+		case OpEqual:
+			old = append(old, l.Text)
+			new = append(new, l.Text)
+			equal++
+		case OpDelete:
+			old = append(old, l.Text)
+		case OpInsert:
+			new = append(new, l.Text)
+		}
+	}
+	if strings.Join(old, "\n") != strings.Join(a, "\n") || strings.Join(new, "\n") != strings.Join(b, "\n") {
+		t.Fatalf("diff does not turn %q into %q: %v", a, b, diff)
+	}
+	return equal
+}
+
+// lcsLength is the textbook dynamic programming solution.
+func lcsLength(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			switch {
+			case a[i] == b[j]:
+				cur[j+1] = prev[j] + 1
+			case prev[j+1] > cur[j]:
+				cur[j+1] = prev[j+1]
+			default:
+				cur[j+1] = cur[j]
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func randomLines(r *rand.Rand, n, alphabet int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprint(r.Intn(alphabet))
+	}
+	return lines
+}
+
+func TestLinesMinimal(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		a := randomLines(r, r.Intn(20), 1+r.Intn(6))
+		b := randomLines(r, r.Intn(20), 1+r.Intn(6))
+		if got, want := check(t, a, b, diffLines(a, b)), lcsLength(a, b); got != want {
+			t.Fatalf("diff of %q and %q keeps %d lines, want %d", a, b, got, want)
+		}
+	}
+}
+
+// TestLinesLarge checks that memory use does not grow with the number of
+// edits, which reach tens of thousands here.
+func TestLinesLarge(t *testing.T) {
+	if testing.Short() {
+		t.Skip("slow")
+	}
+	const n = 20000
+	r := rand.New(rand.NewSource(1))
+	unrelatedA := make([]string, n)
+	unrelatedB := make([]string, n)
+	for i := range unrelatedA {
+		unrelatedA[i] = fmt.Sprintf("a %d", i)
+		unrelatedB[i] = fmt.Sprintf("b %d", i)
+	}
+
+	tests := []struct {
+		name string
+		a, b []string
+	}{
+		{"unrelated", unrelatedA, unrelatedB},
+		{"rewritten", randomLines(r, n, 1000), randomLines(r, n, 1000)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			diff := diffLines(tt.a, tt.b)
+			runtime.ReadMemStats(&after)
+			if alloc := after.TotalAlloc - before.TotalAlloc; alloc > 64<<20 {
+				t.Errorf("diff allocated %d MB", alloc>>20)
+			}
+			check(t, tt.a, tt.b, diff)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package diff
+
+import "strings"
+
+// Conflict markers used in merged output.
+const (
+	markerOurs   = "<<<<<<< yours"
+	markerSep    = "======="
+	markerTheirs = ">>>>>>> on disk"
+)
+
+// MergeChunk is a region of a three-way merge. Stable chunks are unchanged on
+// both sides; conflict chunks were changed differently on each side.
+type MergeChunk struct {
+	Conflict bool     `json:"conflict"`
+	Base     []string `json:"base,omitempty"`
+	Ours     []string `json:"ours,omitempty"`
+	Theirs   []string `json:"theirs,omitempty"`
+	Merged   []string `json:"merged,omitempty"` // resolved lines for non-conflicting chunks
+}
+
+// MergeResult is the outcome of a three-way merge.
+type MergeResult struct {
+	Merged    string       `json:"merged"` // full text, with conflict markers if any
+	Conflicts int          `json:"conflicts"`
+	Chunks    []MergeChunk `json:"chunks"`
+}
+
+// Merge3 merges ours and theirs, both derived from base, diff3 style.
+// Regions changed on only one side are taken from that side; regions changed
+// identically on both sides are taken once; anything else is a conflict.
+func Merge3(base, ours, theirs string) MergeResult {
+	b, o, t := SplitLines(base), SplitLines(ours), SplitLines(theirs)
+
+	// Map each base line to its matching line on either side.
+	mo := make(map[int]int)
+	for _, p := range matches(b, o) {
+		mo[p[0]] = p[1]
+	}
+	mt := make(map[int]int)
+	for _, p := range matches(b, t) {
+		mt[p[0]] = p[1]
+	}
+
+	var res MergeResult
+	var merged []string
+	i, j, k := 0, 0, 0
+	for i < len(b) || j < len(o) || k < len(t) {
+		// Stable line: base[i] is matched at the current position on both sides.
+		if oj, ok := mo[i]; ok && oj == j {
+			if tk, ok := mt[i]; ok && tk == k {
+				start := i
+				for i < len(b) && mo[i] == j && mt[i] == k && hasKey(mo, i) && hasKey(mt, i) {
+					i++
+					j++
+					k++
+				}
+				res.Chunks = append(res.Chunks, MergeChunk{Merged: b[start:i]})
+				merged = append(merged, b[start:i]...)
+				continue
+			}
+		}
+
+		// Unstable region: runs until the next base line matched on both sides.
+		ni := i
+		for ni < len(b) && !(hasKey(mo, ni) && hasKey(mt, ni)) {
+			ni++
+		}
+		nj, nk := len(o), len(t)
+		if ni < len(b) {
+			nj, nk = mo[ni], mt[ni]
+		}
+		chunk := MergeChunk{Base: b[i:ni], Ours: o[j:nj], Theirs: t[k:nk]}
+		switch {
+		case equalLines(chunk.Ours, chunk.Base):
+			chunk.Merged = chunk.Theirs
+		case equalLines(chunk.Theirs, chunk.Base), equalLines(chunk.Ours, chunk.Theirs):
+			chunk.Merged = chunk.Ours
+		default:
+			chunk.Conflict = true
+			res.Conflicts++
+		}
+		if chunk.Conflict {
+			merged = append(merged, markerOurs)
+			merged = append(merged, chunk.Ours...)
+			merged = append(merged, markerSep)
+			merged = append(merged, chunk.Theirs...)
+			merged = append(merged, markerTheirs)
+		} else {
+			merged = append(merged, chunk.Merged...)
+		}
+		res.Chunks = append(res.Chunks, chunk)
+		i, j, k = ni, nj, nk
+	}
+
+	if len(merged) > 0 {
+		res.Merged = strings.Join(merged, "\n")
+		// The final newline is merged like a line: a side that added or
+		// removed it wins.
+		newline := strings.HasSuffix(theirs, "\n")
+		if strings.HasSuffix(ours, "\n") != strings.HasSuffix(base, "\n") {
+			newline = strings.HasSuffix(ours, "\n")
+		}
+		if newline {
+			res.Merged += "\n"
+		}
+	}
+	return res
+}
+
+func hasKey(m map[int]int, k int) bool {
+	_, ok := m[k]
+	return ok
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
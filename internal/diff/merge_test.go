@@ -0,0 +1,29 @@
+package diff
+
+import "testing"
+
+func TestMerge3TrailingNewline(t *testing.T) {
+	tests := []struct {
+		name               string
+		base, ours, theirs string
+		want               string
+	}{
+		{"none", "{\n\"a\": 1\n}", "{\n\"a\": 2\n}", "{\n\"a\": 1\n}", "{\n\"a\": 2\n}"},
+		{"both", "a\nx\nb\n", "a\nx\nB\n", "A\nx\nb\n", "A\nx\nB\n"},
+		{"ours adds", "a\nb", "a\nb\n", "A\nb", "A\nb\n"},
+		{"ours removes", "a\nb\n", "a\nb", "A\nb\n", "A\nb"},
+		{"theirs adds", "a\nb", "A\nb", "a\nb\n", "A\nb\n"},
+		{"theirs removes", "a\nb\n", "A\nb\n", "a\nb", "A\nb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := Merge3(tt.base, tt.ours, tt.theirs)
+			if res.Conflicts != 0 {
+				t.Fatalf("Merge3 reported %d conflicts", res.Conflicts)
+			}
+			if res.Merged != tt.want {
+				t.Errorf("Merge3 = %q, want %q", res.Merged, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+// Package dupes finds files with identical content and files that are near
+// duplicates of each other, such as CLAUDE.md files copied between projects
+// and edited since.
+package dupes
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+)
+
+// maxFileSize skips files too large to be hand-written configuration.
+const maxFileSize = 2 << 20
+
+// DefaultThreshold is the minimum similarity, in percent, for near duplicates.
+const DefaultThreshold = 80
+
+// document is a readable text file taking part in the analysis.
+type document struct {
+	entry models.FileEntry
+	text  string
+	hash  string
+}
+
+// Analyze groups files with identical content and files whose similarity is
+// at least threshold percent. Empty, binary and very large files are ignored.
+func Analyze(files []models.FileEntry, threshold float64) models.DuplicateReport {
+	docs := load(files)
+	report := models.DuplicateReport{
+		Exact:     exactGroups(docs),
+		Similar:   []models.SimilarGroup{},
+		Threshold: threshold,
+	}
+
+	// Near duplicates are searched among distinct contents only: each exact
+	// group is represented by its original.
+	redundant := make(map[string]bool)
+	for _, g := range report.Exact {
+		for _, f := range g.Files[1:] {
+			redundant[f.ID] = true
+		}
+		report.WastedSize += g.Size * int64(len(g.Files)-1)
+	}
+	var distinct []document
+	for _, d := range docs {
+		if !redundant[d.entry.ID] {
+			distinct = append(distinct, d)
+		}
+	}
+	report.Similar = similarGroups(distinct, threshold)
+	return report
+}
+
+// Exact returns the groups of files with identical content.
+func Exact(files []models.FileEntry) []models.DuplicateGroup {
+	return exactGroups(load(files))
+}
+
+// Similarity returns the similarity of two texts in percent, as the Jaccard
+// index of their word shingles.
+func Similarity(a, b string) float64 {
+	return percent(jaccard(shingles(a), shingles(b)))
+}
+
+func load(files []models.FileEntry) []document {
+	var docs []document
+	for _, f := range files {
+		if f.Size == 0 || f.Size > maxFileSize {
+			continue
+		}
+		data, err := os.ReadFile(f.Path)
+		if err != nil || !utf8.Valid(data) || len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+		docs = append(docs, document{
+			entry: f,
+			text:  string(data),
+			hash:  fmt.Sprintf("%x", sha256.Sum256(data)),
+		})
+	}
+	return docs
+}
+
+func exactGroups(docs []document) []models.DuplicateGroup {
+	byHash := make(map[string][]models.FileEntry)
+	for _, d := range docs {
+		byHash[d.hash] = append(byHash[d.hash], d.entry)
+	}
+
+	groups := []models.DuplicateGroup{}
+	for hash, files := range byHash {
+		if len(files) < 2 {
+			continue
+		}
+		sortOriginalFirst(files)
+		groups = append(groups, models.DuplicateGroup{Hash: hash, Size: files[0].Size, Files: files})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		wi := groups[i].Size * int64(len(groups[i].Files)-1)
+		wj := groups[j].Size * int64(len(groups[j].Files)-1)
+		if wi != wj {
+			return wi > wj
+		}
+		return groups[i].Files[0].Path < groups[j].Files[0].Path
+	})
+	return groups
+}
+
+// sortOriginalFirst orders copies so the one most likely to be the source
+// comes first: global files, then the oldest, then by path.
+func sortOriginalFirst(files []models.FileEntry) {
+	sort.Slice(files, func(i, j int) bool {
+		a, b := files[i], files[j]
+		if (a.Scope == models.ScopeGlobal) != (b.Scope == models.ScopeGlobal) {
+			return a.Scope == models.ScopeGlobal
+		}
+		if !a.ModTime.Equal(b.ModTime) {
+			return a.ModTime.Before(b.ModTime)
+		}
+		return a.Path < b.Path
+	})
+}
+
+func similarGroups(docs []document, threshold float64) []models.SimilarGroup {
+	// Candidate pairs come from MinHash LSH: documents sharing a band bucket.
+	sets := make([]map[uint64]bool, len(docs))
+	buckets := make(map[uint64][]int)
+	for i, d := range docs {
+		if len(strings.Fields(d.text)) < shingleSize {
+			continue // too short to compare meaningfully
+		}
+		sets[i] = shingles(d.text)
+		sig := minhash(sets[i])
+		for _, key := range sig.bandKeys() {
+			buckets[key] = append(buckets[key], i)
+		}
+	}
+
+	type pairKey struct{ a, b int }
+	checked := make(map[pairKey]bool)
+	parent := make([]int, len(docs))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	var pairs []struct {
+		a, b int
+		sim  float64
+	}
+	for _, members := range buckets {
+		for x := 0; x < len(members); x++ {
+			for y := x + 1; y < len(members); y++ {
+				k := pairKey{members[x], members[y]}
+				if checked[k] {
+					continue
+				}
+				checked[k] = true
+				sim := percent(jaccard(sets[k.a], sets[k.b]))
+				if sim < threshold {
+					continue
+				}
+				pairs = append(pairs, struct {
+					a, b int
+					sim  float64
+				}{k.a, k.b, sim})
+				parent[find(k.a)] = find(k.b)
+			}
+		}
+	}
+
+	byRoot := make(map[int]*models.SimilarGroup)
+	members := make(map[int][]int)
+	for _, p := range pairs {
+		root := find(p.a)
+		g := byRoot[root]
+		if g == nil {
+			g = &models.SimilarGroup{}
+			byRoot[root] = g
+		}
+		g.Pairs = append(g.Pairs, models.SimilarPair{
+			A:          docs[p.a].entry.ID,
+			B:          docs[p.b].entry.ID,
+			Similarity: p.sim,
+		})
+		members[root] = append(members[root], p.a, p.b)
+	}
+
+	groups := make([]models.SimilarGroup, 0, len(byRoot))
+	for root, g := range byRoot {
+		seen := make(map[int]bool)
+		for _, i := range members[root] {
+			if !seen[i] {
+				seen[i] = true
+				g.Files = append(g.Files, docs[i].entry)
+			}
+		}
+		sort.Slice(g.Files, func(i, j int) bool { return g.Files[i].Path < g.Files[j].Path })
+		sort.Slice(g.Pairs, func(i, j int) bool {
+			if g.Pairs[i].Similarity != g.Pairs[j].Similarity {
+				return g.Pairs[i].Similarity > g.Pairs[j].Similarity
+			}
+			return g.Pairs[i].A+g.Pairs[i].B < g.Pairs[j].A+g.Pairs[j].B
+		})
+		groups = append(groups, *g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if a, b := groups[i].Pairs[0].Similarity, groups[j].Pairs[0].Similarity; a != b {
+			return a > b
+		}
+		return groups[i].Files[0].Path < groups[j].Files[0].Path
+	})
+	return groups
+}
+
+// percent converts a ratio to a percentage rounded to one decimal.
+func percent(r float64) float64 {
+	return math.Round(r*1000) / 10
+}
@@ -0,0 +1,103 @@
+package dupes
+
+import (
+	"hash/fnv"
+	"strings"
+)
+
+const (
+	shingleSize = 5   // words per shingle
+	numHashes   = 128 // MinHash signature length
+	bandRows    = 4   // rows per LSH band; numHashes/bandRows bands
+)
+
+// shingles returns the set of hashed word shingles of text, after folding
+// case and whitespace. Texts shorter than a shingle yield one shingle.
+func shingles(text string) map[uint64]bool {
+	words := strings.Fields(strings.ToLower(text))
+	set := make(map[uint64]bool)
+	if len(words) == 0 {
+		return set
+	}
+	k := min(shingleSize, len(words))
+	for i := 0; i+k <= len(words); i++ {
+		h := fnv.New64a()
+		for _, w := range words[i : i+k] {
+			h.Write([]byte(w))
+			h.Write([]byte{0})
+		}
+		set[h.Sum64()] = true
+	}
+	return set
+}
+
+// signature is the MinHash signature of a shingle set: for each of
+// numHashes hash functions, the minimum hash over the set.
+type signature [numHashes]uint64
+
+func minhash(set map[uint64]bool) signature {
+	var sig signature
+	for i := range sig {
+		sig[i] = ^uint64(0)
+	}
+	for s := range set {
+		for i := range sig {
+			if h := mix(s ^ seeds[i]); h < sig[i] {
+				sig[i] = h
+			}
+		}
+	}
+	return sig
+}
+
+// bandKeys hashes each LSH band of the signature. Two files sharing any band
+// key become a candidate pair.
+func (sig *signature) bandKeys() []uint64 {
+	keys := make([]uint64, 0, numHashes/bandRows)
+	for b := 0; b < numHashes; b += bandRows {
+		h := uint64(b)
+		for _, v := range sig[b : b+bandRows] {
+			h = mix(h ^ v)
+		}
+		keys = append(keys, h)
+	}
+	return keys
+}
+
+// jaccard returns |a ∩ b| / |a ∪ b|.
+func jaccard(a, b map[uint64]bool) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	inter := 0
+	for s := range a {
+		if b[s] {
+			inter++
+		}
+	}
+	union := len(a) + len(b) - inter
+	if union == 0 {
+		return 1
+	}
+	return float64(inter) / float64(union)
+}
+
+// seeds derives one seed per hash function.
+var seeds = func() (s [numHashes]uint64) {
+	x := uint64(0x9e3779b97f4a7c15)
+	for i := range s {
+		x = mix(x + uint64(i))
+		s[i] = x
+	}
+	return s
+}()
+
+// mix is the SplitMix64 finalizer, a fast 64-bit hash.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,130 @@
+package fsutil
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// rename is os.Rename, replaced in tests to simulate a failed commit.
+var rename = os.Rename
+
+// WriteFileAtomic replaces the contents of path without ever leaving a
+// partially written file behind. The data is written to a temporary file in
+// the same directory, flushed to disk and renamed over the target.
+//
+// If the target already exists its permission bits and (where supported)
+// ownership are carried over, so executable hook scripts stay executable.
+// New files are created with perm. Symlinks are followed and the link
+// target is replaced, leaving the link itself intact.
+func WriteFileAtomic(path string, data []byte, perm fs.FileMode) error {
+	target := path
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		target = resolved
+	}
+
+	mode := perm
+	info, statErr := os.Stat(target)
+	if statErr == nil {
+		if !info.Mode().IsRegular() {
+			return fmt.Errorf("%s is not a regular file", path)
+		}
+		mode = info.Mode().Perm()
+	} else if !os.IsNotExist(statErr) {
+		return statErr
+	}
+
+	dir := filepath.Dir(target)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(target)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	committed := false
+	defer func() {
+		if !committed {
+			tmp.Close()
+			os.Remove(tmpName)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		return err
+	}
+	if statErr == nil {
+		copyOwner(tmp, info)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := rename(tmpName, target); err != nil {
+		return err
+	}
+	committed = true
+
+	return syncDir(dir)
+}
+
+// WriteFileExclusive creates path with data, failing with an error
+// satisfying os.IsExist if it already exists. Missing parent directories are
+// created. Like WriteFileAtomic, readers never observe a partial file.
+func WriteFileExclusive(path string, data []byte, perm fs.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if _, err := os.Lstat(path); err == nil {
+		return &fs.PathError{Op: "create", Path: path, Err: fs.ErrExist}
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	// A hard link never replaces an existing file, unlike rename.
+	if err := os.Link(tmpName, path); err != nil {
+		if os.IsExist(err) {
+			return err
+		}
+		// Filesystems without hard links: fall back to an exclusive create.
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(data); err != nil {
+			f.Close()
+			os.Remove(path)
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	return syncDir(dir)
+}
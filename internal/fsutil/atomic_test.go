@@ -0,0 +1,66 @@
+package fsutil
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestWriteFileAtomicKeepsMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits are not meaningful on windows")
+	}
+	path := filepath.Join(t.TempDir(), "hook.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteFileAtomic(path, []byte("#!/bin/sh\nexit 0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.Mode().Perm(); got != 0755 {
+		t.Errorf("mode after save = %v, want %v", got, os.FileMode(0755))
+	}
+	if data, _ := os.ReadFile(path); string(data) != "#!/bin/sh\nexit 0\n" {
+		t.Errorf("content = %q", data)
+	}
+}
+
+func TestWriteFileAtomicCleansUp(t *testing.T) {
+	errRename := errors.New("rename failed")
+	rename = func(string, string) error { return errRename }
+	t.Cleanup(func() { rename = os.Rename })
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "CLAUDE.md")
+	if err := os.WriteFile(path, []byte("original"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteFileAtomic(path, []byte("replacement"), 0644); !errors.Is(err, errRename) {
+		t.Fatalf("err = %v, want %v", err, errRename)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "CLAUDE.md" {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("directory holds %q, want only CLAUDE.md", names)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "original" {
+		t.Errorf("content = %q, want original", data)
+	}
+}
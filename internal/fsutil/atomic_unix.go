@@ -0,0 +1,30 @@
+//go:build !windows
+
+package fsutil
+
+import (
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// copyOwner gives f the same owner and group as the file described by info.
+// Failures are ignored: an unprivileged process can only keep ownership it
+// already has, and the write itself should not fail because of that.
+func copyOwner(f *os.File, info fs.FileInfo) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return
+	}
+	_ = f.Chown(int(st.Uid), int(st.Gid))
+}
+
+// syncDir flushes a directory entry so a completed rename survives a crash.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,14 @@
+//go:build windows
+
+package fsutil
+
+import (
+	"io/fs"
+	"os"
+)
+
+// copyOwner is a no-op on Windows, where files inherit the directory ACL.
+func copyOwner(f *os.File, info fs.FileInfo) {}
+
+// syncDir is a no-op on Windows, which cannot fsync directories.
+func syncDir(dir string) error { return nil }
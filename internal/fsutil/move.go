@@ -0,0 +1,121 @@
+package fsutil
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// Move renames src to dst, falling back to copy-and-delete when they are on
+// different filesystems. Directories are moved recursively. dst must not
+// exist: if it does, or appears while moving, Move fails with an error
+// satisfying errors.Is(err, os.ErrExist) and leaves it untouched.
+func Move(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	err := renameNoReplace(src, dst)
+	if err == nil || !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+
+	// Claim dst before copying so that removing it on failure cannot remove
+	// anything but our own partial copy. Copy creates files exclusively.
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		if err := os.Mkdir(dst, info.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+	if err := Copy(src, dst); err != nil {
+		if !errors.Is(err, os.ErrExist) || info.IsDir() {
+			os.RemoveAll(dst)
+		}
+		return err
+	}
+	return os.RemoveAll(src)
+}
+
+// renameNoReplace renames src to dst on the same filesystem without replacing
+// an existing dst. A file is hard-linked to dst and then unlinked, since a
+// link never replaces its target; directories go through renameDir.
+func renameNoReplace(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return renameDir(src, dst)
+	}
+	if err := os.Link(src, dst); err != nil {
+		if errors.Is(err, os.ErrExist) || errors.Is(err, syscall.EXDEV) {
+			return err
+		}
+		// Filesystems without hard links: check, then rename.
+		if _, err := os.Lstat(dst); err == nil {
+			return &os.LinkError{Op: "rename", Old: src, New: dst, Err: os.ErrExist}
+		}
+		return os.Rename(src, dst)
+	}
+	return os.Remove(src)
+}
+
+// Copy copies a file or directory tree from src to dst, preserving
+// permission bits. Symlinks are recreated rather than followed.
+func Copy(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		link, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(link, dst)
+	case info.IsDir():
+		if err := os.MkdirAll(dst, info.Mode().Perm()); err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(src)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if err := Copy(filepath.Join(src, e.Name()), filepath.Join(dst, e.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return copyFile(src, dst, info.Mode().Perm())
+	}
+}
+
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
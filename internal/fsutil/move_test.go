@@ -0,0 +1,72 @@
+package fsutil
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMove(t *testing.T) {
+	tests := []struct {
+		name     string
+		dir      bool   // move a directory rather than a file
+		existing string // what is at dst beforehand: "", "file" or "dir"
+		wantErr  error
+	}{
+		{"file", false, "", nil},
+		{"file over file", false, "file", os.ErrExist},
+		{"file over dir", false, "dir", os.ErrExist},
+		{"dir", true, "", nil},
+		{"dir over file", true, "file", os.ErrExist},
+		{"dir over empty dir", true, "dir", os.ErrExist},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			src := filepath.Join(root, "src")
+			dst := filepath.Join(root, "sub", "dst")
+			srcFile := src
+			if tt.dir {
+				srcFile = filepath.Join(src, "SKILL.md")
+				os.Mkdir(src, 0755)
+			}
+			if err := os.WriteFile(srcFile, []byte("moved"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			os.MkdirAll(filepath.Dir(dst), 0755)
+			switch tt.existing {
+			case "file":
+				os.WriteFile(dst, []byte("kept"), 0644)
+			case "dir":
+				os.Mkdir(dst, 0755)
+			}
+
+			err := Move(src, dst)
+			if !errors.Is(err, tt.wantErr) || (err != nil) != (tt.wantErr != nil) {
+				t.Fatalf("Move = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if _, err := os.Stat(srcFile); err != nil {
+					t.Errorf("source lost after failed move: %v", err)
+				}
+				if tt.existing == "file" {
+					if data, _ := os.ReadFile(dst); string(data) != "kept" {
+						t.Errorf("existing target changed to %q", data)
+					}
+				}
+				return
+			}
+			if _, err := os.Lstat(src); !os.IsNotExist(err) {
+				t.Errorf("source still exists after move: %v", err)
+			}
+			moved := dst
+			if tt.dir {
+				moved = filepath.Join(dst, "SKILL.md")
+			}
+			if data, _ := os.ReadFile(moved); string(data) != "moved" {
+				t.Errorf("moved content = %q, want %q", data, "moved")
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+//go:build !windows
+
+package fsutil
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// renameDir renames the directory src to dst without replacing an existing
+// dst. It creates dst empty first, which fails if dst exists, and renames
+// over it; the rename refuses to replace a directory that is not empty.
+// os.Rename refuses any existing directory, so this calls rename(2) itself.
+func renameDir(src, dst string) error {
+	if err := os.Mkdir(dst, 0700); err != nil {
+		return err
+	}
+	if err := syscall.Rename(src, dst); err != nil {
+		os.Remove(dst)
+		if errors.Is(err, syscall.ENOTEMPTY) {
+			err = os.ErrExist
+		}
+		return &os.LinkError{Op: "rename", Old: src, New: dst, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+//go:build windows
+
+package fsutil
+
+import "os"
+
+// renameDir renames the directory src to dst. Windows never renames a
+// directory over an existing path; the check only gives that failure the
+// same error as elsewhere.
+func renameDir(src, dst string) error {
+	if _, err := os.Lstat(dst); err == nil {
+		return &os.LinkError{Op: "rename", Old: src, New: dst, Err: os.ErrExist}
+	}
+	return os.Rename(src, dst)
+}
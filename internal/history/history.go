@@ -0,0 +1,273 @@
+package history
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/fsutil"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+)
+
+// Layout of the history directory:
+//
+//	<dir>/objects/<aa>/<hash>   file contents, addressed by their SHA-256
+//	<dir>/logs/<pathhash>.json  ordered revision log for one file path
+//
+// Logs are keyed by absolute path rather than FileEntry ID so that history
+// survives rescans and files that are deleted and later recreated.
+const (
+	objectsDir = "objects"
+	logsDir    = "logs"
+)
+
+// maxRevisions caps how many revisions are kept per file.
+const maxRevisions = 100
+
+// ErrNotFound is returned for unknown revisions.
+var ErrNotFound = errors.New("revision not found")
+
+// Store is a local, content-addressed store of prior file versions.
+type Store struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// revisionLog is the on-disk revision list of one file, oldest first.
+type revisionLog struct {
+	Path      string            `json:"path"`
+	Revisions []models.Revision `json:"revisions"`
+	// Seq numbers the revisions recorded for the path, making their IDs unique.
+	Seq int `json:"seq"`
+}
+
+// New creates a history store rooted at dir.
+func New(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Record stores content as a revision of path. Consecutive identical
+// revisions are collapsed, so recording unchanged content is a no-op.
+func (s *Store) Record(path string, content []byte, source string) (models.Revision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	log, err := s.readLog(path)
+	if err != nil {
+		return models.Revision{}, err
+	}
+	hash := Hash(content)
+	if n := len(log.Revisions); n > 0 && log.Revisions[n-1].Hash == hash {
+		return log.Revisions[n-1], nil
+	}
+
+	if err := s.writeObject(hash, content); err != nil {
+		return models.Revision{}, err
+	}
+	log.Seq++
+	rev := models.Revision{
+		ID:      fmt.Sprintf("%d-%s", log.Seq, hash[:12]),
+		Hash:    hash,
+		SavedAt: time.Now(),
+		Size:    int64(len(content)),
+		Source:  source,
+	}
+	log.Revisions = append(log.Revisions, rev)
+	dropped := log.trim()
+	if err := s.writeLog(log); err != nil {
+		return models.Revision{}, err
+	}
+	return rev, s.prune(dropped)
+}
+
+// List returns the revisions of path, newest first.
+func (s *Store) List(path string) ([]models.Revision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	log, err := s.readLog(path)
+	if err != nil {
+		return nil, err
+	}
+	revs := make([]models.Revision, len(log.Revisions))
+	for i, r := range log.Revisions {
+		revs[len(revs)-1-i] = r
+	}
+	return revs, nil
+}
+
+// Get returns the content of a revision of path.
+func (s *Store) Get(path, id string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	log, err := s.readLog(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range log.Revisions {
+		if r.ID == id {
+			return os.ReadFile(s.objectPath(r.Hash))
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// Move re-keys the history of oldPath to newPath, e.g. after a rename.
+// Any history already recorded for newPath is kept in front.
+func (s *Store) Move(oldPath, newPath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	from, err := s.readLog(oldPath)
+	if err != nil || len(from.Revisions) == 0 {
+		return err
+	}
+	to, err := s.readLog(newPath)
+	if err != nil {
+		return err
+	}
+	// Renumber the moved revisions so their IDs stay unique under newPath.
+	for _, r := range from.Revisions {
+		to.Seq++
+		r.ID = fmt.Sprintf("%d-%s", to.Seq, r.Hash[:12])
+		to.Revisions = append(to.Revisions, r)
+	}
+	dropped := to.trim()
+	if err := s.writeLog(to); err != nil {
+		return err
+	}
+	if err := os.Remove(s.logPath(oldPath)); err != nil {
+		return err
+	}
+	return s.prune(dropped)
+}
+
+// trim drops the oldest revisions beyond maxRevisions and returns the
+// content hashes no longer referenced by the log.
+func (l *revisionLog) trim() []string {
+	n := len(l.Revisions) - maxRevisions
+	if n <= 0 {
+		return nil
+	}
+	dropped := l.Revisions[:n]
+	l.Revisions = l.Revisions[n:]
+
+	kept := make(map[string]bool, len(l.Revisions))
+	for _, r := range l.Revisions {
+		kept[r.Hash] = true
+	}
+	var hashes []string
+	for _, r := range dropped {
+		if !kept[r.Hash] {
+			kept[r.Hash] = true
+			hashes = append(hashes, r.Hash)
+		}
+	}
+	return hashes
+}
+
+// prune deletes the objects with the given hashes that no log references.
+// Objects are shared between files, so every log is consulted; this only
+// happens once a file's history has reached maxRevisions.
+func (s *Store) prune(hashes []string) error {
+	if len(hashes) == 0 {
+		return nil
+	}
+	unused := make(map[string]bool, len(hashes))
+	for _, h := range hashes {
+		unused[h] = true
+	}
+	logs, err := filepath.Glob(filepath.Join(s.dir, logsDir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, p := range logs {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("pruning history: %w", err)
+		}
+		var log revisionLog
+		if err := json.Unmarshal(data, &log); err != nil {
+			// Keep what a corrupt log may still refer to.
+			return fmt.Errorf("corrupt history log %s: %w", p, err)
+		}
+		log.upgrade()
+		for _, r := range log.Revisions {
+			delete(unused, r.Hash)
+		}
+	}
+	for h := range unused {
+		if err := os.Remove(s.objectPath(h)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("pruning history: %w", err)
+		}
+	}
+	return nil
+}
+
+// Hash returns the content address used for revision IDs.
+func Hash(content []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(content))
+}
+
+func (s *Store) objectPath(hash string) string {
+	return filepath.Join(s.dir, objectsDir, hash[:2], hash)
+}
+
+func (s *Store) logPath(path string) string {
+	return filepath.Join(s.dir, logsDir, Hash([]byte(path))[:32]+".json")
+}
+
+func (s *Store) writeObject(hash string, content []byte) error {
+	p := s.objectPath(hash)
+	if _, err := os.Stat(p); err == nil {
+		return nil // already stored
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(p, content, 0600)
+}
+
+func (s *Store) readLog(path string) (*revisionLog, error) {
+	log := &revisionLog{Path: path}
+	data, err := os.ReadFile(s.logPath(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return log, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, log); err != nil {
+		return nil, fmt.Errorf("corrupt history log for %s: %w", path, err)
+	}
+	log.upgrade()
+	return log, nil
+}
+
+// upgrade fills in the hashes of revisions recorded when IDs were the
+// content hashes themselves. Those IDs are kept so existing links still work.
+func (l *revisionLog) upgrade() {
+	for i, r := range l.Revisions {
+		if r.Hash == "" {
+			l.Revisions[i].Hash = r.ID
+		}
+	}
+}
+
+func (s *Store) writeLog(log *revisionLog) error {
+	p := s.logPath(log.Path)
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(p, data, 0600)
+}
@@ -0,0 +1,96 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+)
+
+func TestRecordIDs(t *testing.T) {
+	s := New(t.TempDir())
+	var ids []string
+	for _, content := range []string{"a", "b", "b", "a"} {
+		rev, err := s.Record("/p/CLAUDE.md", []byte(content), "save")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, rev.ID)
+	}
+	if ids[1] != ids[2] {
+		t.Errorf("recording unchanged content gave a new revision %q after %q", ids[2], ids[1])
+	}
+	if ids[0] == ids[3] {
+		t.Errorf("revisions with equal content share ID %q", ids[0])
+	}
+	for i, want := range []string{"a", "b", "b", "a"} {
+		got, err := s.Get("/p/CLAUDE.md", ids[i])
+		if err != nil || string(got) != want {
+			t.Errorf("Get(%q) = %q, %v, want %q", ids[i], got, err, want)
+		}
+	}
+}
+
+func TestRecordPrunesObjects(t *testing.T) {
+	dir := t.TempDir()
+	s := New(dir)
+	// The first revision is shared with another file and must survive.
+	if _, err := s.Record("/other/CLAUDE.md", []byte("v0"), "save"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < maxRevisions+2; i++ {
+		if _, err := s.Record("/p/CLAUDE.md", []byte(fmt.Sprint("v", i)), "save"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	revs, err := s.List("/p/CLAUDE.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revs) != maxRevisions {
+		t.Fatalf("%d revisions kept, want %d", len(revs), maxRevisions)
+	}
+	exists := func(content string) bool {
+		_, err := os.Stat(s.objectPath(Hash([]byte(content))))
+		return err == nil
+	}
+	if !exists("v0") {
+		t.Error("object still used by another file was deleted")
+	}
+	if exists("v1") {
+		t.Error("object of a trimmed revision was kept")
+	}
+	if !exists("v2") {
+		t.Error("object of a kept revision was deleted")
+	}
+}
+
+func TestLegacyLog(t *testing.T) {
+	dir := t.TempDir()
+	s := New(dir)
+	hash := Hash([]byte("old"))
+	if err := s.writeObject(hash, []byte("old")); err != nil {
+		t.Fatal(err)
+	}
+	legacy := revisionLog{Path: "/p/CLAUDE.md", Revisions: []models.Revision{{ID: hash, Size: 3}}}
+	data, _ := json.Marshal(legacy)
+	os.MkdirAll(filepath.Join(dir, logsDir), 0700)
+	if err := os.WriteFile(s.logPath(legacy.Path), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := s.Get(legacy.Path, hash); err != nil || string(got) != "old" {
+		t.Errorf("Get(legacy ID) = %q, %v", got, err)
+	}
+	rev, err := s.Record(legacy.Path, []byte("old"), "save")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rev.ID != hash {
+		t.Errorf("recording the latest legacy content again gave new revision %q", rev.ID)
+	}
+}
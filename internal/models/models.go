@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/diff"
+)
 
 // Category groups related Claude files together.
 type Category string
@@ -12,6 +16,7 @@ const (
 	CategoryPlans    Category = "plans"
 	CategorySkills   Category = "skills"
 	CategoryAgents   Category = "agents"
+	CategoryCommands Category = "commands"
 	CategoryDebug    Category = "debug"
 	CategoryProject  Category = "project"
 	CategoryOther    Category = "other"
@@ -29,13 +34,14 @@ type CategoryInfo struct {
 func AllCategories() []CategoryInfo {
 	return []CategoryInfo{
 		{CategoryMemory, "Memories", "MEMORY.md and per-project memory files", "brain"},
-		{CategorySettings, "Settings", "Claude configuration and settings files", "settings"},
+		{CategorySettings, "Settings", "Claude settings, MCP server and configuration files", "settings"},
 		{CategoryTodos, "Todos", "Task and todo tracking files", "checklist"},
 		{CategoryPlans, "Plans", "Planning and strategy documents", "map"},
 		{CategorySkills, "Skills", "Custom skill definitions", "sparkles"},
 		{CategoryAgents, "Agents", "Custom agent definitions (.md with YAML frontmatter)", "bot"},
+		{CategoryCommands, "Commands", "Custom slash commands in .claude/commands/", "terminal"},
 		{CategoryDebug, "Debug", "Debug and diagnostic log files", "bug"},
-		{CategoryProject, "Project Config", "CLAUDE.md and .clauderc project files", "folder"},
+		{CategoryProject, "Project Config", "CLAUDE.md, CLAUDE.local.md and .clauderc project files", "folder"},
 		{CategoryOther, "Other", "Other Claude-related files", "file"},
 	}
 }
@@ -50,43 +56,91 @@ const (
 
 // FileEntry represents a single discovered Claude file.
 type FileEntry struct {
-	ID          string   `json:"id"`
-	Path        string   `json:"path"`
-	RelPath     string   `json:"relPath"`
-	Name        string   `json:"name"`
-	DisplayName string   `json:"displayName"`
-	Category    Category `json:"category"`
-	Scope       Scope    `json:"scope"`
-	ProjectName string   `json:"projectName,omitempty"`
-	Size        int64    `json:"size"`
+	ID          string    `json:"id"`
+	Path        string    `json:"path"`
+	RelPath     string    `json:"relPath"`
+	Name        string    `json:"name"`
+	DisplayName string    `json:"displayName"`
+	Category    Category  `json:"category"`
+	Scope       Scope     `json:"scope"`
+	ProjectName string    `json:"projectName,omitempty"`
+	ProjectPath string    `json:"projectPath,omitempty"` // project directory, when it exists
+	Root        string    `json:"root,omitempty"`        // search path the file was found under
+	Workspace   string    `json:"workspace,omitempty"`   // name of that search path's workspace
+	Size        int64     `json:"size"`
 	ModTime     time.Time `json:"modTime"`
-	ReadOnly    bool     `json:"readOnly"`
+	ReadOnly    bool      `json:"readOnly"`
+	Local       bool      `json:"local,omitempty"` // personal, uncommitted file (settings.local.json, CLAUDE.local.md)
+
+	Command *CommandMeta `json:"command,omitempty"` // set for CategoryCommands
+}
+
+// CommandMeta is the frontmatter of a custom slash command.
+type CommandMeta struct {
+	Name         string   `json:"name"` // invocation name without the slash, e.g. "frontend:build"
+	Description  string   `json:"description,omitempty"`
+	ArgumentHint string   `json:"argumentHint,omitempty"`
+	AllowedTools []string `json:"allowedTools,omitempty"`
+	Model        string   `json:"model,omitempty"`
 }
 
 // BulkDeleteRequest is the payload for deleting multiple files.
+// Reason is recorded on the trashed items (e.g. "cleanup").
 type BulkDeleteRequest struct {
-	IDs []string `json:"ids"`
+	IDs    []string `json:"ids"`
+	Reason string   `json:"reason,omitempty"`
 }
 
 // FileContent is returned when reading a file's contents.
 type FileContent struct {
 	FileEntry
 	Content string `json:"content"`
+	ETag    string `json:"etag"`
 }
 
-// SaveRequest is the payload for saving a file.
+// SaveRequest is the payload for saving a file. Base is the content the
+// client started editing from; when present, conflicts include a three-way merge.
+// JSON files are validated before saving unless Force is set.
 type SaveRequest struct {
-	Content string `json:"content"`
+	Content string  `json:"content"`
+	Base    *string `json:"base,omitempty"`
+	Force   bool    `json:"force,omitempty"` // save even if validation fails
+}
+
+// SaveConflict is returned with 409 when a save's If-Match no longer
+// matches the file on disk.
+type SaveConflict struct {
+	Error   string            `json:"error"`
+	ETag    string            `json:"etag"`
+	Current string            `json:"current"`
+	Yours   string            `json:"yours"`
+	Base    *string           `json:"base,omitempty"`
+	Diff    []diff.Line       `json:"diff"`
+	Merge   *diff.MergeResult `json:"merge,omitempty"`
 }
 
 // ScanResult holds the complete scan output.
 type ScanResult struct {
-	RootPath   string      `json:"rootPath"`
-	Files      []FileEntry `json:"files"`
-	ScannedAt  time.Time   `json:"scannedAt"`
+	Roots      []ScanRoot     `json:"roots"`
+	Files      []FileEntry    `json:"files"`
+	ScannedAt  time.Time      `json:"scannedAt"`
 	Categories []CategoryInfo `json:"categories"`
 }
 
+// ScanRoot is a directory searched by a scan, and the workspace it belongs
+// to. Several roots may share a workspace.
+type ScanRoot struct {
+	Path      string `json:"path"`
+	Workspace string `json:"workspace"`
+}
+
+// ScanProgress reports how far a running scan has got.
+type ScanProgress struct {
+	Dirs  int64 `json:"dirs"`  // directories read
+	Files int64 `json:"files"` // files matched
+	Done  bool  `json:"done"`
+}
+
 // CleanupReason describes why a file is suggested for cleanup.
 type CleanupReason string
 
@@ -94,6 +148,13 @@ const (
 	ReasonEmptyContent CleanupReason = "empty_content"
 	ReasonEmptyFile    CleanupReason = "empty_file"
 	ReasonStale        CleanupReason = "stale"
+
+	// ReasonOrphanedProject marks a ~/.claude/projects directory whose
+	// project directory no longer exists.
+	ReasonOrphanedProject CleanupReason = "orphaned_project"
+
+	// ReasonDuplicate marks a file whose exact content exists in another file.
+	ReasonDuplicate CleanupReason = "duplicate"
 )
 
 // CleanupItem is a file flagged for potential cleanup.
@@ -102,11 +163,428 @@ type CleanupItem struct {
 	Reason      CleanupReason `json:"reason"`
 	ReasonLabel string        `json:"reasonLabel"`
 	DaysSince   int           `json:"daysSince,omitempty"`
+	Rule        string        `json:"rule"` // name of the CleanupRule that matched
 }
 
-// CleanupResult holds grouped cleanup suggestions.
+// Cleanup rule actions.
+const (
+	CleanupFlag = "flag" // suggest the file for cleanup (default)
+	CleanupKeep = "keep" // never suggest the file; later rules are skipped
+)
+
+// CleanupRule flags (or protects) files that match all of its criteria.
+// Rules are evaluated in order and the first matching rule decides.
+type CleanupRule struct {
+	Name   string            `json:"name"`
+	Match  CleanupMatch      `json:"match"`
+	When   CleanupConditions `json:"when"`
+	Action string            `json:"action,omitempty"` // CleanupFlag or CleanupKeep
+	Reason CleanupReason     `json:"reason,omitempty"`
+	// Label is shown for flagged files. {age}, {size}, {content} and
+	// {original} are replaced with the file's age in days, its size, a
+	// description of its empty content and the path of the file it duplicates.
+	Label string `json:"label,omitempty"`
+}
+
+// CleanupMatch selects files by what they are. Empty lists match anything.
+type CleanupMatch struct {
+	Categories []Category `json:"categories,omitempty"`
+	Scopes     []Scope    `json:"scopes,omitempty"`
+	// Paths are glob patterns; "**" spans directories and "~" is the home
+	// directory. Absolute and "~/" patterns match the whole path, others
+	// its trailing components.
+	Paths []string `json:"paths,omitempty"`
+}
+
+// CleanupConditions test a file's state. Unset conditions always hold.
+type CleanupConditions struct {
+	OlderThanDays *int   `json:"olderThanDays,omitempty"`
+	MinSize       *int64 `json:"minSize,omitempty"`
+	MaxSize       *int64 `json:"maxSize,omitempty"`
+	// Empty requires content that is blank, [], {} or null.
+	Empty bool `json:"empty,omitempty"`
+	// ContentRegex requires the content to match a regular expression.
+	ContentRegex string `json:"contentRegex,omitempty"`
+	// Duplicate requires another file with identical content, of which this
+	// is not the original.
+	Duplicate bool `json:"duplicate,omitempty"`
+}
+
+// CleanupRuleSet is the active rule list and where it came from.
+type CleanupRuleSet struct {
+	Path    string        `json:"path"`
+	Default bool          `json:"default"` // true when no rules file exists
+	Rules   []CleanupRule `json:"rules"`
+}
+
+// OrphanedProject is a ~/.claude/projects directory left behind by a
+// deleted project, with everything in it: memory files and transcripts.
+type OrphanedProject struct {
+	Dir          string        `json:"dir"` // encoded directory name
+	Path         string        `json:"path"`
+	Name         string        `json:"name"` // best-effort project name
+	Reason       CleanupReason `json:"reason"`
+	FileCount    int           `json:"fileCount"`
+	SessionCount int           `json:"sessionCount"`
+	Size         int64         `json:"size"`
+	ModTime      time.Time     `json:"modTime"` // newest file inside
+}
+
+// CleanupResult holds grouped cleanup suggestions. Files inside orphaned
+// project directories are reported with their directory, not as Items.
 type CleanupResult struct {
-	Items      []CleanupItem `json:"items"`
-	TotalSize  int64         `json:"totalSize"`
-	TotalCount int           `json:"totalCount"`
+	Items      []CleanupItem     `json:"items"`
+	Orphans    []OrphanedProject `json:"orphans"`
+	TotalSize  int64             `json:"totalSize"`
+	TotalCount int               `json:"totalCount"`
+}
+
+// OrphanCleanupRequest is the payload for trashing orphaned project
+// directories, identified by their encoded names.
+type OrphanCleanupRequest struct {
+	Dirs []string `json:"dirs"`
+}
+
+// SearchSpan marks a highlighted range within a snippet, in characters.
+type SearchSpan struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// SearchMatch is a single matching line within a file.
+type SearchMatch struct {
+	Line       int          `json:"line"`
+	Snippet    string       `json:"snippet"`
+	Highlights []SearchSpan `json:"highlights"`
+}
+
+// SearchHit groups the matching lines of one file.
+type SearchHit struct {
+	FileEntry
+	Matches    []SearchMatch `json:"matches"`
+	MatchCount int           `json:"matchCount"`
+}
+
+// SearchResult is returned by the content search endpoint.
+type SearchResult struct {
+	Query     string      `json:"query"`
+	Mode      string      `json:"mode"`
+	Hits      []SearchHit `json:"hits"`
+	TotalHits int         `json:"totalHits"`
+}
+
+// TrashItem describes a file or directory moved to the ClaudeShelf trash.
+type TrashItem struct {
+	ID           string    `json:"id"`
+	OriginalPath string    `json:"originalPath"`
+	RelPath      string    `json:"relPath"`
+	Name         string    `json:"name"`
+	DisplayName  string    `json:"displayName,omitempty"`
+	Category     Category  `json:"category,omitempty"`
+	IsDir        bool      `json:"isDir"`
+	Size         int64     `json:"size"`
+	DeletedAt    time.Time `json:"deletedAt"`
+	Reason       string    `json:"reason"`
+}
+
+// TrashListing is returned when listing the trash.
+type TrashListing struct {
+	Items         []TrashItem `json:"items"`
+	TotalSize     int64       `json:"totalSize"`
+	RetentionDays int         `json:"retentionDays"`
+}
+
+// TrashPurgeRequest selects which trash items to purge. With neither field
+// set, items older than the retention period are purged.
+type TrashPurgeRequest struct {
+	All           bool `json:"all"`
+	OlderThanDays *int `json:"olderThanDays,omitempty"`
+}
+
+// Revision is a stored prior version of a file. Each recording gets its own
+// ID; Hash addresses the content, which revisions with equal content share.
+type Revision struct {
+	ID      string    `json:"id"`
+	Hash    string    `json:"hash"`
+	SavedAt time.Time `json:"savedAt"`
+	Size    int64     `json:"size"`
+	Source  string    `json:"source"` // "disk", "save" or "restore"
+}
+
+// RevisionContent is returned when fetching a single revision.
+type RevisionContent struct {
+	Revision
+	Content string `json:"content"`
+}
+
+// RevisionDiff compares two revisions of a file.
+type RevisionDiff struct {
+	From    string      `json:"from"`
+	To      string      `json:"to"`
+	Hunks   []diff.Hunk `json:"hunks"`
+	Unified string      `json:"unified"`
+}
+
+// Project is a project directory discovered from scanned files.
+type Project struct {
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	FileCount int    `json:"fileCount"`
+}
+
+// CreateRequest is the payload for creating a new Claude file. Project is
+// a discovered project name or an absolute project directory; it is
+// required for project scope.
+type CreateRequest struct {
+	Category Category `json:"category"`
+	Scope    Scope    `json:"scope"`
+	Project  string   `json:"project,omitempty"`
+	Name     string   `json:"name"`
+	Content  *string  `json:"content,omitempty"` // overrides the template
+}
+
+// MoveRequest is the payload for renaming a file or moving it between
+// scopes. Leaving Scope and Project empty renames in place.
+type MoveRequest struct {
+	Name    string `json:"name,omitempty"`
+	Scope   Scope  `json:"scope,omitempty"`
+	Project string `json:"project,omitempty"`
+}
+
+// CopyRequest is the payload for copying a file into other projects.
+// Projects are discovered project names or absolute project directories.
+type CopyRequest struct {
+	Projects  []string `json:"projects"`
+	Overwrite bool     `json:"overwrite"`
+}
+
+// CopyStatus is the outcome of copying to one target.
+type CopyStatus string
+
+const (
+	CopyCopied      CopyStatus = "copied"
+	CopyOverwritten CopyStatus = "overwritten"
+	CopyConflict    CopyStatus = "conflict"
+	CopySkipped     CopyStatus = "skipped"
+	CopyFailed      CopyStatus = "error"
+)
+
+// CopyTarget reports what happened for one target project.
+type CopyTarget struct {
+	Project string      `json:"project"`
+	Path    string      `json:"path,omitempty"`
+	Status  CopyStatus  `json:"status"`
+	Error   string      `json:"error,omitempty"`
+	Files   []FileEntry `json:"files,omitempty"`
+}
+
+// CopyResult summarises a copy operation.
+type CopyResult struct {
+	Targets   []CopyTarget `json:"targets"`
+	Copied    int          `json:"copied"`
+	Conflicts int          `json:"conflicts"`
+	Errors    int          `json:"errors"`
+}
+
+// SettingsSource is one settings file in the resolution hierarchy.
+type SettingsSource struct {
+	Scope  string `json:"scope"` // "user", "project", "local" or "managed"
+	Path   string `json:"path"`
+	Exists bool   `json:"exists"`
+	Error  string `json:"error,omitempty"`
+}
+
+// SettingValue is a value contributed by one settings scope.
+type SettingValue struct {
+	Source string      `json:"source"`
+	Value  interface{} `json:"value"`
+}
+
+// SettingProvenance explains where an effective setting came from. Pointer
+// is an RFC 6901 JSON pointer. Scalars report the winning Source and the
+// values it Overrode; arrays are merged across scopes, so each of their Items
+// carries its own source.
+type SettingProvenance struct {
+	Pointer    string         `json:"pointer"`
+	Value      interface{}    `json:"value"`
+	Source     string         `json:"source,omitempty"`
+	Overridden []SettingValue `json:"overridden,omitempty"`
+	Items      []SettingValue `json:"items,omitempty"`
+}
+
+// EffectiveSettings is the merged settings view for one project.
+type EffectiveSettings struct {
+	Project    Project                `json:"project"`
+	Sources    []SettingsSource       `json:"sources"`
+	Effective  map[string]interface{} `json:"effective"`
+	Provenance []SettingProvenance    `json:"provenance"`
+}
+
+// ValidationError is a problem found in a file's content. Line and Column
+// are 1-based; Pointer is an RFC 6901 JSON pointer to the offending value.
+type ValidationError struct {
+	Pointer string `json:"pointer"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Message string `json:"message"`
+}
+
+// ValidationFailure is returned with 422 when a save is rejected.
+type ValidationFailure struct {
+	Error  string            `json:"error"`
+	Schema string            `json:"schema,omitempty"`
+	Errors []ValidationError `json:"errors"`
+}
+
+// SessionSummary describes one Claude Code conversation transcript
+// (~/.claude/projects/<encoded>/<session>.jsonl).
+type SessionSummary struct {
+	ID           string    `json:"id"`
+	Project      string    `json:"project"`
+	ProjectPath  string    `json:"projectPath,omitempty"` // decoded project directory, when it exists
+	ProjectDir   string    `json:"projectDir"`            // encoded directory name under ~/.claude/projects
+	Cwd          string    `json:"cwd,omitempty"`
+	Path         string    `json:"path"`
+	Size         int64     `json:"size"`
+	StartedAt    time.Time `json:"startedAt"`
+	EndedAt      time.Time `json:"endedAt"`
+	MessageCount int       `json:"messageCount"`
+	FirstPrompt  string    `json:"firstPrompt,omitempty"`
+	Tools        []string  `json:"tools"`
+	Models       []string  `json:"models"`
+}
+
+// SessionList is a page of sessions, newest first.
+type SessionList struct {
+	Sessions []SessionSummary `json:"sessions"`
+	Total    int              `json:"total"`
+	Offset   int              `json:"offset"`
+	Limit    int              `json:"limit"`
+}
+
+// SessionTurn is one user or assistant message of a transcript. Cursor is
+// the byte offset of the following line; pass it back to continue reading.
+type SessionTurn struct {
+	UUID        string    `json:"uuid,omitempty"`
+	Role        string    `json:"role"`
+	Timestamp   time.Time `json:"timestamp"`
+	Model       string    `json:"model,omitempty"`
+	Text        string    `json:"text,omitempty"`
+	Tools       []string  `json:"tools,omitempty"`      // tools invoked in this message
+	ToolResult  bool      `json:"toolResult,omitempty"` // message only carries tool output
+	IsMeta      bool      `json:"isMeta,omitempty"`
+	IsSidechain bool      `json:"isSidechain,omitempty"`
+	Cursor      int64     `json:"cursor"`
+}
+
+// TokenUsage counts the tokens billed for one or more API responses.
+type TokenUsage struct {
+	Input         int64 `json:"input"`
+	Output        int64 `json:"output"`
+	CacheCreation int64 `json:"cacheCreation"`
+	CacheRead     int64 `json:"cacheRead"`
+}
+
+// Add accumulates o into u.
+func (u *TokenUsage) Add(o TokenUsage) {
+	u.Input += o.Input
+	u.Output += o.Output
+	u.CacheCreation += o.CacheCreation
+	u.CacheRead += o.CacheRead
+}
+
+// UsageRecord is the token usage of one session on one day with one model.
+// ProjectID tells projects apart that share a name: it is the project
+// directory, or the encoded directory name under ~/.claude/projects when
+// the project directory no longer exists.
+type UsageRecord struct {
+	SessionID string `json:"sessionId"`
+	Project   string `json:"project"` // display name
+	ProjectID string `json:"projectId"`
+	Date      string `json:"date"` // YYYY-MM-DD, local time
+	Model     string `json:"model"`
+	Messages  int    `json:"messages"`
+	TokenUsage
+}
+
+// UsageRow is usage aggregated under one key (a session, project, model or
+// day). Project rows are keyed by the UsageRecord.ProjectID.
+type UsageRow struct {
+	Key       string  `json:"key"`
+	Project   string  `json:"project,omitempty"`   // set for session and project rows
+	ProjectID string  `json:"projectId,omitempty"` // set for session rows
+	Messages  int     `json:"messages"`
+	CostUSD   float64 `json:"costUsd"`
+	TokenUsage
+}
+
+// UsageReport is returned by /api/usage. Costs are estimates from public
+// list prices; models without a known price are listed in UnpricedModels
+// and count as zero.
+type UsageReport struct {
+	From           string     `json:"from,omitempty"`
+	To             string     `json:"to,omitempty"`
+	Total          UsageRow   `json:"total"`
+	BySession      []UsageRow `json:"bySession"`
+	ByProject      []UsageRow `json:"byProject"`
+	ByModel        []UsageRow `json:"byModel"`
+	ByDay          []UsageRow `json:"byDay"`
+	UnpricedModels []string   `json:"unpricedModels"`
+}
+
+// DuplicateGroup is a set of files with identical content. Files[0] is the
+// one considered the original; see dupes.Analyze.
+type DuplicateGroup struct {
+	Hash  string      `json:"hash"`
+	Size  int64       `json:"size"`
+	Files []FileEntry `json:"files"`
+}
+
+// SimilarPair is the similarity of two files, as the Jaccard index of their
+// word shingles (0–100).
+type SimilarPair struct {
+	A          string  `json:"a"` // file IDs
+	B          string  `json:"b"`
+	Similarity float64 `json:"similarity"`
+}
+
+// SimilarGroup is a set of files connected by near-duplicate pairs.
+type SimilarGroup struct {
+	Files []FileEntry   `json:"files"`
+	Pairs []SimilarPair `json:"pairs"`
+}
+
+// DuplicateReport is returned by /api/duplicates.
+type DuplicateReport struct {
+	Exact      []DuplicateGroup `json:"exact"`
+	Similar    []SimilarGroup   `json:"similar"`
+	Threshold  float64          `json:"threshold"`  // minimum similarity, in percent
+	WastedSize int64            `json:"wastedSize"` // bytes held by redundant exact copies
+}
+
+// DuplicateDiff compares two files found by duplicate analysis.
+type DuplicateDiff struct {
+	A          FileEntry   `json:"a"`
+	B          FileEntry   `json:"b"`
+	Similarity float64     `json:"similarity"`
+	Hunks      []diff.Hunk `json:"hunks"`
+	Unified    string      `json:"unified"`
+}
+
+// FileEvent types pushed on /api/events.
+const (
+	EventAdd    = "add"
+	EventModify = "modify"
+	EventDelete = "delete"
+	EventRescan = "rescan" // the file list was rebuilt; clients should reload it
+	EventScan   = "scan"   // a full scan is running
+)
+
+// FileEvent reports a change to the scan result, detected by watching the
+// scanned directories, or the progress of a full scan.
+type FileEvent struct {
+	Type     string        `json:"type"`
+	ID       string        `json:"id,omitempty"`
+	File     *FileEntry    `json:"file,omitempty"`     // the new entry, for add and modify
+	Progress *ScanProgress `json:"progress,omitempty"` // for scan
 }
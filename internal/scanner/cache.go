@@ -0,0 +1,126 @@
+package scanner
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/fsutil"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+)
+
+// cacheVersion is bumped whenever the cache format or the meaning of its
+// contents changes; caches of other versions are discarded.
+const cacheVersion = 3
+
+// racyWindow is how close to the previous scan a modification time may be
+// before it is no longer trusted: a change made in the same timestamp tick
+// as the scan would otherwise go unnoticed on filesystems with coarse times.
+const racyWindow = 2 * time.Second
+
+// scanCache remembers what the previous scan saw. A directory whose mtime is
+// unchanged still has the same entries, so it is not listed again; a file
+// whose size, mtime and permissions are unchanged keeps its FileEntry.
+type scanCache struct {
+	Version   int                   `json:"version"`
+	Rules     string                `json:"rules"` // rules.key of the scan
+	ScannedAt time.Time             `json:"scannedAt"`
+	Dirs      map[string]cachedDir  `json:"dirs"`
+	Files     map[string]cachedFile `json:"files"`
+}
+
+// cachedDir is the relevant part of a directory listing: subdirectories
+// that may be descended into, the Claude files it contains and whether it
+// is the root of a git repository. Depth and hidden-directory rules depend
+// on the search path and are applied later.
+type cachedDir struct {
+	ModTime time.Time `json:"modTime"`
+	Dirs    []string  `json:"dirs,omitempty"`
+	Files   []string  `json:"files,omitempty"`
+	Repo    bool      `json:"repo,omitempty"`
+}
+
+// cachedFile is a FileEntry with the mode of the file it was built from: a
+// chmod changes ReadOnly but leaves the modification time alone.
+type cachedFile struct {
+	Entry models.FileEntry `json:"entry"`
+	Mode  os.FileMode      `json:"mode"`
+}
+
+func newScanCache() *scanCache {
+	return &scanCache{
+		Version: cacheVersion,
+		Dirs:    make(map[string]cachedDir),
+		Files:   make(map[string]cachedFile),
+	}
+}
+
+// UseCache makes scans reuse the results of earlier scans, persisted in the
+// file at path, so that unchanged directories are not listed again.
+func (s *Scanner) UseCache(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cachePath = path
+	s.cache = loadCache(path)
+	if s.cache.Rules != s.rules.key {
+		s.cache = newScanCache() // listings were filtered differently
+	}
+}
+
+func loadCache(path string) *scanCache {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return newScanCache()
+	}
+	c := newScanCache()
+	if err := json.Unmarshal(data, c); err != nil || c.Version != cacheVersion {
+		return newScanCache()
+	}
+	return c
+}
+
+func (s *Scanner) saveCache() {
+	if s.cachePath == "" {
+		return
+	}
+	data, err := json.Marshal(s.cache)
+	if err == nil {
+		if err = os.MkdirAll(filepath.Dir(s.cachePath), 0700); err == nil {
+			err = fsutil.WriteFileAtomic(s.cachePath, data, 0600)
+		}
+	}
+	if err != nil {
+		log.Printf("Saving scan cache failed: %v", err)
+	}
+}
+
+// trusted reports whether a cached modification time still describes the
+// current state, i.e. it is unchanged and not racy.
+func (c *scanCache) trusted(cached, current time.Time) bool {
+	return cached.Equal(current) && current.Before(c.ScannedAt.Add(-racyWindow))
+}
+
+// listDir reads a directory, keeping its subdirectories and the names of
+// Claude files.
+func listDir(path string, modTime time.Time, r *rules) (cachedDir, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return cachedDir{}, err
+	}
+	listing := cachedDir{ModTime: modTime}
+	for _, e := range entries {
+		name := e.Name()
+		if name == ".git" { // a directory, or a file in worktrees and submodules
+			listing.Repo = true
+		}
+		switch {
+		case e.IsDir():
+			listing.Dirs = append(listing.Dirs, name)
+		case r.claudeFile(filepath.Join(path, name), name):
+			listing.Files = append(listing.Files, name)
+		}
+	}
+	return listing, nil
+}
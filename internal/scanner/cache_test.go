@@ -0,0 +1,130 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// makeTree creates projects*files Claude files: projects directories, each
+// with a CLAUDE.md and a .claude directory holding the rest. Modification
+// times are set in the past so a cache trusts them.
+func makeTree(tb testing.TB, dir string, projects, files int) {
+	tb.Helper()
+	old := time.Now().Add(-time.Hour)
+	for p := 0; p < projects; p++ {
+		project := filepath.Join(dir, fmt.Sprintf("p%04d", p))
+		claude := filepath.Join(project, ".claude", "commands")
+		if err := os.MkdirAll(claude, 0755); err != nil {
+			tb.Fatal(err)
+		}
+		paths := []string{filepath.Join(project, "CLAUDE.md")}
+		for f := 1; f < files; f++ {
+			paths = append(paths, filepath.Join(claude, fmt.Sprintf("c%03d.md", f)))
+		}
+		for _, path := range paths {
+			if err := os.WriteFile(path, []byte("# "+filepath.Base(path)+"\n"), 0644); err != nil {
+				tb.Fatal(err)
+			}
+			os.Chtimes(path, old, old)
+		}
+		for _, d := range []string{claude, filepath.Dir(claude), project} {
+			os.Chtimes(d, old, old)
+		}
+	}
+	os.Chtimes(dir, old, old)
+}
+
+// BenchmarkRescan compares scanning a tree of 100k files from scratch with
+// rescanning it unchanged, when unchanged directories are not listed again.
+func BenchmarkRescan(b *testing.B) {
+	const projects, files = 1000, 100
+	tree := b.TempDir()
+	makeTree(b, tree, projects, files)
+	roots := []Root{{Path: tree}}
+
+	scan := func(b *testing.B, sc *Scanner) {
+		result, err := sc.ScanContext(context.Background(), nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(result.Files) != projects*files {
+			b.Fatalf("found %d files, want %d", len(result.Files), projects*files)
+		}
+	}
+
+	b.Run("cold", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			sc, err := New(roots, Config{})
+			if err != nil {
+				b.Fatal(err)
+			}
+			scan(b, sc)
+		}
+	})
+
+	b.Run("warm", func(b *testing.B) {
+		sc, err := New(roots, Config{})
+		if err != nil {
+			b.Fatal(err)
+		}
+		sc.UseCache(filepath.Join(b.TempDir(), "scan-cache.json"))
+		scan(b, sc) // fills the cache
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			scan(b, sc)
+		}
+	})
+}
+
+func TestCacheReadOnly(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can write read-only files")
+	}
+	tree := t.TempDir()
+	makeTree(t, tree, 1, 1)
+	sc, err := New([]Root{{Path: tree}}, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc.UseCache(filepath.Join(t.TempDir(), "scan-cache.json"))
+	if _, err := sc.Scan(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(tree, "p0000", "CLAUDE.md"), 0444); err != nil {
+		t.Fatal(err)
+	}
+	result, err := sc.Scan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Files) != 1 || !result.Files[0].ReadOnly {
+		t.Errorf("after chmod 0444, rescan returned %+v, want one read-only file", result.Files)
+	}
+}
+
+func TestRulesKey(t *testing.T) {
+	two, one := 2, 1
+	configs := []Config{
+		{},
+		{Excludes: []string{"build/"}},
+		{MaxDepth: &two},
+		{Roots: []Root{{Path: "/work", MaxDepth: &one}}},
+		{DiscoverRepos: true},
+		{Extensions: []string{".md"}},
+	}
+	seen := make(map[string]int)
+	for i, c := range configs {
+		r, err := c.compile(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if j, dup := seen[r.key]; dup {
+			t.Errorf("configs %d and %d share cache key %s", j, i, r.key)
+		}
+		seen[r.key] = i
+	}
+}
@@ -0,0 +1,416 @@
+package scanner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+)
+
+// Config customises what a Scanner looks at. It is read from config.json in
+// the ClaudeShelf data directory; the zero value scans the default locations
+// with the built-in rules.
+type Config struct {
+	// Roots are scanned in addition to the default locations. Roots with a
+	// name form a workspace; the others belong to DefaultWorkspace.
+	Roots []Root `json:"roots,omitempty"`
+	// Excludes are gitignore-style patterns for files and directories to
+	// skip, added to DefaultExcludes. See compileExclude.
+	Excludes []string `json:"excludes,omitempty"`
+	// MaxDepth is how many levels of subdirectories below a broad search
+	// path (such as ~/projects) are searched for Claude files. Negative
+	// means unlimited; nil means DefaultMaxDepth. Deeper directories are
+	// not entered, except a .claude directory of the last searched level,
+	// which is searched in full.
+	MaxDepth *int `json:"maxDepth,omitempty"`
+	// Extensions accepted for files inside .claude directories, replacing
+	// DefaultExtensions. Files without an extension are always accepted.
+	Extensions []string `json:"extensions,omitempty"`
+	// DiscoverRepos searches broad search paths for git repositories at
+	// any depth. Beyond MaxDepth, a repository contributes the Claude files
+	// at its root and the CLAUDE.md files of its subdirectories, which
+	// Claude Code loads when working there. Repositories nested in another
+	// are searched as part of the outer one.
+	DiscoverRepos bool `json:"discoverRepos,omitempty"`
+}
+
+// Root is a search path. Path may be a glob such as "/work/*", and "~/" is
+// the home directory.
+type Root struct {
+	Name     string `json:"name,omitempty"` // workspace name
+	Path     string `json:"path"`
+	MaxDepth *int   `json:"maxDepth,omitempty"` // overrides Config.MaxDepth
+}
+
+// DefaultWorkspace is the workspace of the well-known locations and of
+// unnamed configured roots.
+const DefaultWorkspace = "default"
+
+// ParseRoot parses a -path flag value, "dir" or "name=dir". An existing
+// directory whose name contains "=" is taken as is.
+func ParseRoot(arg string) Root {
+	if _, err := os.Stat(arg); err == nil {
+		return Root{Path: expandHome(arg)}
+	}
+	if name, path, ok := strings.Cut(arg, "="); ok && name != "" {
+		return Root{Name: name, Path: expandHome(path)}
+	}
+	return Root{Path: expandHome(arg)}
+}
+
+// expand resolves a root to the existing directories it names. Their
+// workspace is the root's name, else fallback, else the directory's name.
+func (r Root) expand(fallback string) []models.ScanRoot {
+	path := expandHome(r.Path)
+	matches := []string{path}
+	if strings.ContainsAny(path, "*?[") {
+		matches, _ = filepath.Glob(path)
+	}
+
+	var roots []models.ScanRoot
+	for _, m := range matches {
+		if info, err := os.Stat(m); err != nil || !info.IsDir() {
+			continue
+		}
+		name := r.Name
+		if name == "" {
+			name = fallback
+		}
+		if name == "" {
+			name = filepath.Base(m)
+		}
+		roots = append(roots, models.ScanRoot{Path: m, Workspace: name})
+	}
+	return roots
+}
+
+// Defaults used when a Config leaves a setting unset.
+var (
+	DefaultExcludes   = []string{".git", "node_modules", ".venv", "__pycache__"}
+	DefaultExtensions = []string{".md", ".json", ".yaml", ".yml", ".txt", ".toml", ".log", ".sh"}
+)
+
+// DefaultMaxDepth searches project directories directly below a broad search
+// path, e.g. ~/projects/app/CLAUDE.md but not ~/projects/app/sub/CLAUDE.md.
+const DefaultMaxDepth = 1
+
+// LoadConfig reads the scanner configuration at path. A missing file yields
+// the zero Config.
+func LoadConfig(path string) (Config, error) {
+	var cfg Config
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Validate checks the exclude patterns and roots of a Config.
+func (c Config) Validate() error {
+	_, err := c.compile(nil)
+	return err
+}
+
+// rules are the compiled form of a Config, consulted while scanning.
+type rules struct {
+	excludes   []exclude
+	maxDepth   int
+	rootDepth  []rootDepth // per-root overrides of maxDepth
+	discover   bool        // Config.DiscoverRepos
+	extensions map[string]bool
+	// key identifies the settings, so a cache built under others is dropped.
+	key string
+}
+
+// rootDepth is the depth limit of the search paths matching a root.
+type rootDepth struct {
+	pattern string
+	depth   int
+}
+
+// defaultRules apply outside of a Scanner, e.g. to files found by EntriesUnder.
+var defaultRules, _ = Config{}.compile(nil)
+
+// compile prepares the rules for scanning the configured roots and any
+// roots given explicitly.
+func (c Config) compile(extra []Root) (*rules, error) {
+	r := &rules{
+		maxDepth:   DefaultMaxDepth,
+		discover:   c.DiscoverRepos,
+		extensions: make(map[string]bool),
+	}
+	if c.MaxDepth != nil {
+		r.maxDepth = *c.MaxDepth
+	}
+	for _, root := range append(append([]Root(nil), c.Roots...), extra...) {
+		if root.Path == "" {
+			return nil, errors.New("root with empty path")
+		}
+		pattern := expandHome(root.Path)
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid root %q: %w", root.Path, err)
+		}
+		if root.MaxDepth != nil {
+			r.rootDepth = append(r.rootDepth, rootDepth{pattern, *root.MaxDepth})
+		}
+	}
+	excludes := append(append([]string(nil), DefaultExcludes...), c.Excludes...)
+	for _, p := range excludes {
+		e, err := compileExclude(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude %q: %w", p, err)
+		}
+		r.excludes = append(r.excludes, e)
+	}
+
+	exts := c.Extensions
+	if len(exts) == 0 {
+		exts = DefaultExtensions
+	}
+	for _, ext := range exts {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		r.extensions[ext] = true
+	}
+	exts = make([]string, 0, len(r.extensions))
+	for ext := range r.extensions {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+	var rootDepths []string
+	for _, d := range r.rootDepth {
+		rootDepths = append(rootDepths, fmt.Sprintf("%s=%d", d.pattern, d.depth))
+	}
+	key, err := json.Marshal(struct {
+		Excludes   []string
+		MaxDepth   int
+		RootDepth  []string
+		Discover   bool
+		Extensions []string
+	}{excludes, r.maxDepth, rootDepths, r.discover, exts})
+	if err != nil {
+		return nil, err
+	}
+	r.key = string(key)
+	return r, nil
+}
+
+// expandHome resolves a leading "~/" and makes path absolute.
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		path = homeDir() + path[1:]
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// skipDir reports whether the scan of root skips the directory at path:
+// excluded directories, hidden directories other than .claude when
+// scanning a broad directory, and directories beyond the depth limit.
+func (r *rules) skipDir(root, path string, claudeRoot bool) bool {
+	base := filepath.Base(path)
+	if !claudeRoot && base != ".claude" && strings.HasPrefix(base, ".") && base != "." {
+		return true
+	}
+	return r.beyondDepth(root, path, claudeRoot) || r.excluded(root, path, true)
+}
+
+// beyondDepth reports whether every file in the directory at path, and so
+// below it, lies below the depth limit of root. A .claude directory just
+// past the limit is not, as its files count as being beside it. With
+// repository discovery no directory is: a repository may be found in it.
+func (r *rules) beyondDepth(root, path string, claudeRoot bool) bool {
+	return !r.discover && r.tooDeep(root, "", filepath.Join(path, "f"), claudeRoot)
+}
+
+// tooDeep reports whether a file lies below the depth limit of a broad
+// search path. A file in a .claude directory is as deep as the directory,
+// whatever its own depth. Never too deep are the files of a .claude search
+// path and, within the discovered repository repo containing the file if
+// any, the files at its root or in its .claude directory and the CLAUDE.md
+// files anywhere in it.
+func (r *rules) tooDeep(root, repo, path string, claudeRoot bool) bool {
+	if claudeRoot {
+		return false
+	}
+	if repo != "" && (filepath.Dir(path) == repo || isMemoryFile(filepath.Base(path)) ||
+		strings.HasPrefix(path, filepath.Join(repo, ".claude")+string(filepath.Separator))) {
+		return false
+	}
+	depth := r.depthFor(root)
+	if depth < 0 {
+		return false
+	}
+	rel, _ := filepath.Rel(root, path)
+	parts := strings.Split(normPath(rel), "/")
+	for i, name := range parts[:len(parts)-1] {
+		if name == ".claude" {
+			return i > depth
+		}
+	}
+	return len(parts)-1 > depth
+}
+
+// depthFor returns the depth limit of a search path.
+func (r *rules) depthFor(root string) int {
+	for _, d := range r.rootDepth {
+		if ok, _ := filepath.Match(d.pattern, root); ok {
+			return d.depth
+		}
+	}
+	return r.maxDepth
+}
+
+// isMemoryFile reports whether name is a CLAUDE.md file, which Claude Code
+// also loads from the subdirectories of a project.
+func isMemoryFile(name string) bool {
+	nameLower := strings.ToLower(name)
+	return nameLower == "claude.md" || nameLower == "claude.local.md"
+}
+
+// claudeFile reports whether a file is Claude-related.
+func (r *rules) claudeFile(path, name string) bool {
+	nameLower := strings.ToLower(name)
+
+	// Direct Claude config files
+	switch nameLower {
+	case "claude.md", "claude.local.md", ".clauderc", ".mcp.json":
+		return true
+	case ".claude.json":
+		// User-level state and MCP servers; only the one in the home directory.
+		return filepath.Dir(path) == homeDir()
+	}
+
+	// Files inside a .claude (or Claude on Windows) directory: configs, logs
+	// in debug directories, shell scripts (hooks, snapshots) and so on.
+	np := normPath(path)
+	npLower := strings.ToLower(np)
+	if strings.Contains(np, ".claude/") || strings.Contains(npLower, "/claude/") {
+		ext := strings.ToLower(filepath.Ext(name))
+		if r.extensions[ext] {
+			return true
+		}
+		// Also include files with no extension that might be configs
+		if ext == "" && !strings.HasPrefix(name, ".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// exclude is a compiled exclude pattern.
+type exclude struct {
+	re       *regexp.Regexp
+	negate   bool // "!pattern" re-includes what earlier patterns excluded
+	dirOnly  bool // "pattern/" only matches directories
+	anchored bool // contains a "/": matched against the whole path
+}
+
+// compileExclude compiles a gitignore-style pattern:
+//
+//	name, *.bak    match a file or directory name at any depth
+//	build/         matches directories only
+//	/vendor        anchored to the search path being scanned
+//	docs/**/tmp    "**" spans any number of directories
+//	~/work/tmp     anchored patterns also match absolute paths
+//	!keep          re-includes paths excluded by an earlier pattern
+func compileExclude(pattern string) (exclude, error) {
+	var e exclude
+	p := pattern
+	if strings.HasPrefix(p, "!") {
+		e.negate = true
+		p = p[1:]
+	}
+	if strings.HasSuffix(p, "/") {
+		e.dirOnly = true
+		p = strings.TrimRight(p, "/")
+	}
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		p = normPath(homeDir()) + p[1:]
+	}
+	p = normPath(p)
+	e.anchored = strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+	if p == "" {
+		return e, errors.New("empty pattern")
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(p); i++ {
+		switch c := p[i]; c {
+		case '*':
+			if i+1 < len(p) && p[i+1] == '*' {
+				i++
+				if i+1 < len(p) && p[i+1] == '/' {
+					i++
+					b.WriteString("(.*/)?") // "**/" also matches no directory
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	re, err := regexp.Compile(b.String())
+	e.re = re
+	return e, err
+}
+
+// excluded applies the exclude patterns to path, found while scanning root.
+// The last matching pattern decides.
+func (r *rules) excluded(root, path string, isDir bool) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return false
+	}
+	rel = normPath(rel)
+	abs := strings.TrimPrefix(normPath(path), "/")
+	name := filepath.Base(path)
+
+	excluded := false
+	for _, e := range r.excludes {
+		if e.dirOnly && !isDir {
+			continue
+		}
+		var match bool
+		if e.anchored {
+			match = e.re.MatchString(rel) || e.re.MatchString(abs)
+		} else {
+			match = e.re.MatchString(name)
+		}
+		if match {
+			excluded = !e.negate
+		}
+	}
+	return excluded
+}
@@ -0,0 +1,90 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestSkipDirDepth(t *testing.T) {
+	root := filepath.FromSlash("/work")
+	one := 1
+	tests := []struct {
+		name     string
+		discover bool
+		dir      string
+		want     bool
+	}{
+		{"project", false, "app", false},
+		{"below project", false, "app/sub", true},
+		{"project .claude", false, "app/.claude", false},
+		{"inside .claude", false, "app/.claude/commands/deep", false},
+		{"discover", true, "app/sub/deeper", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Config{MaxDepth: &one, DiscoverRepos: tt.discover}.compile(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dir := filepath.Join(root, filepath.FromSlash(tt.dir))
+			if got := r.skipDir(root, dir, false); got != tt.want {
+				t.Errorf("skipDir(%s) = %v, want %v", tt.dir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanMaxDepth(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	work := filepath.Join(home, "work")
+	for _, f := range []string{
+		"app/CLAUDE.md",
+		"app/.claude/settings.json",
+		"app/sub/CLAUDE.md",
+		"app/sub/.claude/settings.json",
+		"app/sub/repo/.git/HEAD",
+		"app/sub/repo/CLAUDE.md",
+	} {
+		path := filepath.Join(work, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	one := 1
+	tests := []struct {
+		discover bool
+		want     []string
+	}{
+		{false, []string{"app/.claude/settings.json", "app/CLAUDE.md"}},
+		{true, []string{"app/.claude/settings.json", "app/CLAUDE.md", "app/sub/repo/CLAUDE.md"}},
+	}
+	for _, tt := range tests {
+		sc, err := New([]Root{{Path: work}}, Config{MaxDepth: &one, DiscoverRepos: tt.discover})
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := sc.Scan()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, f := range result.Files {
+			if rel, err := filepath.Rel(work, f.Path); err == nil && !filepath.IsAbs(rel) && rel[0] != '.' {
+				got = append(got, filepath.ToSlash(rel))
+			}
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("discover=%v: found %v, want %v", tt.discover, got, tt.want)
+		}
+	}
+}
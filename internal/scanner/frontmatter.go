@@ -0,0 +1,109 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// maxFrontmatter bounds how much of a file is read looking for frontmatter.
+const maxFrontmatter = 64 << 10
+
+// parseFrontmatter extracts the top-level keys of a YAML frontmatter block
+// delimited by "---" lines at the start of data. Only the simple forms used
+// by Claude files are understood: scalar values (optionally quoted) and
+// block lists ("- a"), which are returned joined by ", ". Flow lists such as
+// "[a, b]" are returned as written, since argument hints use that form too.
+// It returns nil if data has no frontmatter.
+func parseFrontmatter(data []byte) map[string]string {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 4096), maxFrontmatter)
+	if !sc.Scan() || strings.TrimSpace(strings.TrimPrefix(sc.Text(), "\ufeff")) != "---" {
+		return nil
+	}
+
+	fields := make(map[string]string)
+	var listKey string // key whose value continues as a block list
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), " \t\r")
+		if line == "---" {
+			return fields
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		// Continuation of a block list under the previous key.
+		if item, ok := strings.CutPrefix(trimmed, "- "); ok && listKey != "" {
+			item = unquote(strings.TrimSpace(item))
+			if fields[listKey] == "" {
+				fields[listKey] = item
+			} else {
+				fields[listKey] += ", " + item
+			}
+			continue
+		}
+		if line != trimmed {
+			continue // nested mapping; not needed
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		listKey = ""
+		if value == "" {
+			listKey = key
+		}
+		fields[key] = unquote(value)
+	}
+	return nil // unterminated
+}
+
+// unquote strips matching single or double quotes around s.
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// splitList splits a comma-separated list, optionally in "[a, b]" form,
+// ignoring commas inside parentheses so tool patterns like
+// "Bash(git add:*, -p)" stay intact.
+func splitList(s string) []string {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+		s = s[1 : len(s)-1]
+	}
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		parts = append(parts, last)
+	}
+	var out []string
+	for _, p := range parts {
+		if p = unquote(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
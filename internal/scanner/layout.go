@@ -0,0 +1,135 @@
+package scanner
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+)
+
+// validName matches a single file or directory name segment for created files.
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// GlobalClaudeDir returns the user-level ~/.claude directory.
+func GlobalClaudeDir() string {
+	return filepath.Join(homeDir(), ".claude")
+}
+
+// ClaudeDir returns the .claude directory for a scope: ~/.claude for global,
+// <projectRoot>/.claude for project scope.
+func ClaudeDir(scope models.Scope, projectRoot string) string {
+	if scope == models.ScopeGlobal {
+		return GlobalClaudeDir()
+	}
+	return filepath.Join(projectRoot, ".claude")
+}
+
+// TargetPath resolves where a new file of the given category and name lives:
+//
+//	agents    <claude>/agents/<name>.md
+//	skills    <claude>/skills/<name>/SKILL.md
+//	commands  <claude>/commands/<ns>/<name>.md  (name may be "ns/name" or "ns:name")
+//	project   ~/.claude/CLAUDE.md (global) or <projectRoot>/CLAUDE.md
+//
+// where <claude> is the directory returned by ClaudeDir.
+func TargetPath(cat models.Category, scope models.Scope, projectRoot, name string) (string, error) {
+	if scope == models.ScopeProject && projectRoot == "" {
+		return "", fmt.Errorf("project scope requires a project")
+	}
+	base := ClaudeDir(scope, projectRoot)
+	name = strings.TrimSuffix(name, ".md")
+
+	switch cat {
+	case models.CategoryAgents:
+		if !validName.MatchString(name) {
+			return "", fmt.Errorf("invalid agent name %q", name)
+		}
+		return filepath.Join(base, "agents", name+".md"), nil
+
+	case models.CategorySkills:
+		if !validName.MatchString(name) {
+			return "", fmt.Errorf("invalid skill name %q", name)
+		}
+		return filepath.Join(base, "skills", name, "SKILL.md"), nil
+
+	case models.CategoryCommands:
+		segments := strings.FieldsFunc(name, func(r rune) bool { return r == '/' || r == ':' })
+		if len(segments) == 0 {
+			return "", fmt.Errorf("invalid command name %q", name)
+		}
+		for _, seg := range segments {
+			if !validName.MatchString(seg) {
+				return "", fmt.Errorf("invalid command name %q", name)
+			}
+		}
+		segments[len(segments)-1] += ".md"
+		return filepath.Join(append([]string{base, "commands"}, segments...)...), nil
+
+	case models.CategoryProject:
+		if scope == models.ScopeGlobal {
+			return filepath.Join(base, "CLAUDE.md"), nil
+		}
+		return filepath.Join(projectRoot, "CLAUDE.md"), nil
+	}
+	return "", fmt.Errorf("cannot create files in category %q", cat)
+}
+
+// ProjectRoot returns the project directory a file belongs to, or false if
+// it cannot be determined. Files under ~/.claude/projects/<encoded>/ map to
+// the decoded project directory if it still exists.
+func ProjectRoot(absPath string) (string, bool) {
+	np := normPath(absPath)
+	if idx := strings.Index(np, "/.claude/projects/"); idx != -1 {
+		encoded, _, _ := strings.Cut(np[idx+len("/.claude/projects/"):], "/")
+		return DecodeProjectPath(encoded)
+	}
+	if np == normPath(filepath.Join(homeDir(), ".claude.json")) {
+		return "", false
+	}
+	globalPrefix := normPath(GlobalClaudeDir()) + "/"
+	if strings.HasPrefix(np, globalPrefix) {
+		return "", false
+	}
+	if idx := strings.Index(np, "/.claude/"); idx != -1 {
+		return filepath.FromSlash(np[:idx]), true
+	}
+	if strings.Contains(strings.ToLower(np), "/claude/") {
+		return "", false // Windows %APPDATA%\Claude
+	}
+	return filepath.Dir(absPath), true
+}
+
+// Projects lists the distinct project roots referenced by the given files.
+func Projects(files []models.FileEntry) []models.Project {
+	byPath := make(map[string]*models.Project)
+	for _, f := range files {
+		if f.Scope != models.ScopeProject {
+			continue
+		}
+		root, ok := ProjectRoot(f.Path)
+		if !ok {
+			continue
+		}
+		p := byPath[root]
+		if p == nil {
+			p = &models.Project{Name: filepath.Base(root), Path: root}
+			byPath[root] = p
+		}
+		p.FileCount++
+	}
+
+	projects := make([]models.Project, 0, len(byPath))
+	for _, p := range byPath {
+		projects = append(projects, *p)
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		if projects[i].Name != projects[j].Name {
+			return projects[i].Name < projects[j].Name
+		}
+		return projects[i].Path < projects[j].Path
+	})
+	return projects
+}
@@ -0,0 +1,84 @@
+package scanner
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+)
+
+// OrphanedProjects lists the ~/.claude/projects directories whose project
+// directory no longer exists, largest first. Projects on drives that are
+// not currently mounted are reported too, as they cannot be told apart.
+func OrphanedProjects() ([]models.OrphanedProject, error) {
+	base := filepath.Join(GlobalClaudeDir(), "projects")
+	dirs, err := os.ReadDir(base)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var orphans []models.OrphanedProject
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		if _, ok := DecodeProjectPath(d.Name()); ok {
+			continue
+		}
+		o := models.OrphanedProject{
+			Dir:    d.Name(),
+			Path:   filepath.Join(base, d.Name()),
+			Name:   decodeProjectName(d.Name()),
+			Reason: models.ReasonOrphanedProject,
+		}
+		filepath.WalkDir(o.Path, func(path string, e fs.DirEntry, err error) error {
+			if err != nil || e.IsDir() {
+				return nil
+			}
+			info, err := e.Info()
+			if err != nil {
+				return nil
+			}
+			o.FileCount++
+			o.Size += info.Size()
+			if strings.HasSuffix(e.Name(), ".jsonl") {
+				o.SessionCount++
+			}
+			if info.ModTime().After(o.ModTime) {
+				o.ModTime = info.ModTime()
+			}
+			return nil
+		})
+		orphans = append(orphans, o)
+	}
+
+	sort.Slice(orphans, func(i, j int) bool {
+		if orphans[i].Size != orphans[j].Size {
+			return orphans[i].Size > orphans[j].Size
+		}
+		return orphans[i].Dir < orphans[j].Dir
+	})
+	return orphans, nil
+}
+
+// IsOrphanedProject reports whether dir, an encoded directory name under
+// ~/.claude/projects, exists and no longer maps to a project directory.
+// Since its answer guards deletion, it decodes dir afresh rather than trust
+// a cached result; the decode cache is updated, so OrphanedProjects agrees.
+func IsOrphanedProject(dir string) bool {
+	if dir == "" || dir != filepath.Base(dir) || strings.HasPrefix(dir, ".") {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(GlobalClaudeDir(), "projects", dir))
+	if err != nil || !info.IsDir() {
+		return false
+	}
+	_, ok := decodeProjectPath(dir, true)
+	return !ok
+}
@@ -0,0 +1,119 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// decodeCacheTTL bounds how long a decoded project path is trusted, so that
+// projects created, moved or deleted while the server runs are noticed.
+const decodeCacheTTL = time.Minute
+
+var decodeCache = struct {
+	sync.Mutex
+	m map[string]decodedPath
+}{m: make(map[string]decodedPath)}
+
+type decodedPath struct {
+	path string
+	ok   bool
+	at   time.Time
+}
+
+// DecodeProjectPath resolves a ~/.claude/projects directory name back to the
+// project directory it was encoded from. Because the encoding replaces every
+// non-alphanumeric character with "-", the name is ambiguous; it is resolved
+// by walking the filesystem from the root and matching the encoded names of
+// existing directories, longest match first. It returns false if no existing
+// directory encodes to the name.
+func DecodeProjectPath(encoded string) (string, bool) {
+	return decodeProjectPath(encoded, false)
+}
+
+// decodeProjectPath is DecodeProjectPath, ignoring any cached result if
+// fresh is set. The new result is cached either way.
+func decodeProjectPath(encoded string, fresh bool) (string, bool) {
+	decodeCache.Lock()
+	d, hit := decodeCache.m[encoded]
+	decodeCache.Unlock()
+	if hit && !fresh && time.Since(d.at) < decodeCacheTTL {
+		return d.path, d.ok
+	}
+
+	root, rest, ok := splitEncodedRoot(encoded)
+	var path string
+	if ok {
+		path, ok = resolveEncoded(root, rest)
+	}
+
+	decodeCache.Lock()
+	decodeCache.m[encoded] = decodedPath{path, ok, time.Now()}
+	decodeCache.Unlock()
+	return path, ok
+}
+
+// ProjectFromEncoded returns the name and, when it can be resolved, the path
+// of the project behind a ~/.claude/projects directory name. Unresolvable
+// names fall back to a heuristic name and an empty path.
+func ProjectFromEncoded(encoded string) (name, path string) {
+	if p, ok := DecodeProjectPath(encoded); ok {
+		return filepath.Base(p), p
+	}
+	return decodeProjectName(encoded), ""
+}
+
+// splitEncodedRoot separates the filesystem root from an encoded path:
+// "-home-me-app" → "/", "home-me-app"; on Windows "C--Users-me" → `C:\`, "Users-me".
+func splitEncodedRoot(encoded string) (root, rest string, ok bool) {
+	if runtime.GOOS == "windows" {
+		if len(encoded) < 3 || encoded[1:3] != "--" {
+			return "", "", false
+		}
+		return encoded[:1] + `:\`, encoded[3:], true
+	}
+	if !strings.HasPrefix(encoded, "-") {
+		return "", "", false
+	}
+	return "/", encoded[1:], true
+}
+
+// resolveEncoded finds the directory below dir whose path, encoded and
+// relative to dir, equals rest.
+func resolveEncoded(dir, rest string) (string, bool) {
+	if rest == "" {
+		return dir, true
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", false
+	}
+
+	type candidate struct{ name, enc string }
+	var candidates []candidate
+	for _, e := range entries {
+		enc := EncodeProjectPath(e.Name())
+		if rest != enc && !strings.HasPrefix(rest, enc+"-") {
+			continue
+		}
+		if info, err := os.Stat(filepath.Join(dir, e.Name())); err != nil || !info.IsDir() {
+			continue // follows symlinked directories
+		}
+		candidates = append(candidates, candidate{e.Name(), enc})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return len(candidates[i].enc) > len(candidates[j].enc)
+	})
+
+	for _, c := range candidates {
+		next := strings.TrimPrefix(rest[len(c.enc):], "-")
+		if p, ok := resolveEncoded(filepath.Join(dir, c.name), next); ok {
+			return p, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,103 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDecodeProjectPath(t *testing.T) {
+	base := t.TempDir()
+	for _, d := range []string{"my-app", "my/app2", "split/app/y", "split-app/x", ".dotted.name", "v1.2"} {
+		if err := os.MkdirAll(filepath.Join(base, filepath.FromSlash(d)), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		dir  string // relative to base, encoded as the project path
+		want string // relative to base, "" if it must not resolve
+	}{
+		{"dash in name", "my-app", "my-app"},
+		{"dash is separator", "my/app2", "my/app2"},
+		{"longest name first", "split-app/x", "split-app/x"},
+		{"backtracks", "split/app/y", "split/app/y"},
+		{"dotted", ".dotted.name", ".dotted.name"},
+		{"dotted version", "v1.2", "v1.2"},
+		{"missing", "gone/app", ""},
+		{"missing leaf", "my-app/sub", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := EncodeProjectPath(filepath.Join(base, filepath.FromSlash(tt.dir)))
+			got, ok := DecodeProjectPath(encoded)
+			switch {
+			case tt.want == "" && ok:
+				t.Errorf("DecodeProjectPath(%q) = %q, want no match", encoded, got)
+			case tt.want != "" && got != filepath.Join(base, filepath.FromSlash(tt.want)):
+				t.Errorf("DecodeProjectPath(%q) = %q, %v, want %q", encoded, got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeCacheExpiry(t *testing.T) {
+	project := filepath.Join(t.TempDir(), "app")
+	if err := os.Mkdir(project, 0755); err != nil {
+		t.Fatal(err)
+	}
+	encoded := EncodeProjectPath(project)
+	if _, ok := DecodeProjectPath(encoded); !ok {
+		t.Fatal("existing project not resolved")
+	}
+	os.Remove(project)
+	if _, ok := DecodeProjectPath(encoded); !ok {
+		t.Error("cached result not used within the TTL")
+	}
+
+	decodeCache.Lock()
+	d := decodeCache.m[encoded]
+	d.at = time.Now().Add(-decodeCacheTTL)
+	decodeCache.m[encoded] = d
+	decodeCache.Unlock()
+	if _, ok := DecodeProjectPath(encoded); ok {
+		t.Error("expired result still used")
+	}
+}
+
+func TestIsOrphanedProject(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	project := filepath.Join(home, "src", "app")
+	if err := os.MkdirAll(project, 0755); err != nil {
+		t.Fatal(err)
+	}
+	encoded := EncodeProjectPath(project)
+	if err := os.MkdirAll(ProjectDataDir(project), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if IsOrphanedProject(encoded) {
+		t.Fatal("project with an existing directory reported orphaned")
+	}
+	if orphans, _ := OrphanedProjects(); len(orphans) != 0 {
+		t.Fatalf("listing reports %v", orphans)
+	}
+
+	// The listing caches the project as existing; the check must not.
+	os.Remove(project)
+	if !IsOrphanedProject(encoded) {
+		t.Fatal("deleted project not reported orphaned")
+	}
+	if orphans, _ := OrphanedProjects(); len(orphans) != 1 || orphans[0].Dir != encoded {
+		t.Errorf("after the check, listing reports %v, want %s", orphans, encoded)
+	}
+	for _, dir := range []string{"", ".", "..", "a/b", encoded + "-missing"} {
+		if IsOrphanedProject(dir) {
+			t.Errorf("IsOrphanedProject(%q) = true", dir)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+)
+
+// Relocation is a planned move or copy of a Claude file. Skills are handled
+// as a whole: Src and Dst are then the skill directories and IsDir is set.
+type Relocation struct {
+	Src   string `json:"src"`
+	Dst   string `json:"dst"`
+	IsDir bool   `json:"isDir"`
+}
+
+// location says where a file sits relative to the base directory of its scope.
+type location struct {
+	kind locationKind
+	rel  string // slash-separated path below the base directory
+}
+
+type locationKind int
+
+const (
+	locGlobal      locationKind = iota // under ~/.claude/
+	locProject                         // under <project>/.claude/
+	locProjectData                     // under ~/.claude/projects/<encoded>/
+	locProjectRoot                     // directly in a project root (CLAUDE.md)
+)
+
+// nonAlnum matches the characters Claude Code replaces when encoding a
+// project path into a ~/.claude/projects directory name.
+var nonAlnum = regexp.MustCompile(`[^A-Za-z0-9]`)
+
+// EncodeProjectPath returns the ~/.claude/projects directory name Claude Code
+// uses for a project directory, e.g. /home/me/my.app → -home-me-my-app.
+func EncodeProjectPath(root string) string {
+	return nonAlnum.ReplaceAllString(filepath.ToSlash(root), "-")
+}
+
+// ProjectDataDir returns ~/.claude/projects/<encoded> for a project directory.
+func ProjectDataDir(root string) string {
+	return filepath.Join(GlobalClaudeDir(), "projects", EncodeProjectPath(root))
+}
+
+// locate classifies absPath relative to its scope's base directory.
+func locate(absPath string) (location, error) {
+	np := normPath(absPath)
+	global := normPath(GlobalClaudeDir()) + "/"
+
+	if strings.HasPrefix(np, global) {
+		rel := np[len(global):]
+		if strings.HasPrefix(rel, "projects/") {
+			parts := strings.SplitN(rel, "/", 3)
+			if len(parts) < 3 {
+				return location{}, fmt.Errorf("%s is not inside a project data directory", absPath)
+			}
+			return location{locProjectData, parts[2]}, nil
+		}
+		return location{locGlobal, rel}, nil
+	}
+	if idx := strings.Index(np, "/.claude/"); idx != -1 {
+		return location{locProject, np[idx+len("/.claude/"):]}, nil
+	}
+	if _, ok := ProjectRoot(absPath); ok {
+		return location{locProjectRoot, filepath.Base(absPath)}, nil
+	}
+	return location{}, fmt.Errorf("cannot determine where %s belongs", absPath)
+}
+
+// Relocate plans moving or copying the file at absPath into scope (and, for
+// project scope, projectRoot), optionally renaming it to newName. The file
+// keeps its position relative to the scope's base directory, so an agent
+// stays under agents/ and a project memory stays under memory/.
+//
+// For skills newName renames the skill directory; for commands it may carry
+// a namespace ("frontend/build"); otherwise it replaces the file name and
+// keeps the original extension if none is given.
+func Relocate(absPath string, scope models.Scope, projectRoot, newName string) (Relocation, error) {
+	if scope == models.ScopeProject && projectRoot == "" {
+		return Relocation{}, fmt.Errorf("project scope requires a project")
+	}
+	return plan(absPath, newName, func(kind locationKind, _ string, segments []string) (string, error) {
+		return relocateBase(kind, scope, projectRoot, segments)
+	})
+}
+
+// Rename plans renaming the file at absPath to newName within its current
+// scope, following the same rules as Relocate.
+func Rename(absPath, newName string) (Relocation, error) {
+	if newName == "" {
+		return Relocation{}, fmt.Errorf("missing name")
+	}
+	return plan(absPath, newName, func(_ locationKind, srcBase string, _ []string) (string, error) {
+		return srcBase, nil
+	})
+}
+
+// plan builds a Relocation, asking baseFn for the destination base directory.
+func plan(absPath, newName string, baseFn func(kind locationKind, srcBase string, segments []string) (string, error)) (Relocation, error) {
+	loc, err := locate(absPath)
+	if err != nil {
+		return Relocation{}, err
+	}
+
+	srcBase := strings.TrimSuffix(absPath, filepath.FromSlash(loc.rel))
+	srcSegments := strings.Split(loc.rel, "/")
+	isDir := len(srcSegments) >= 3 && srcSegments[0] == "skills"
+	if isDir {
+		// The unit of a skill is its directory: skills/<name>/...
+		srcSegments = srcSegments[:2]
+	}
+
+	segments := srcSegments
+	if newName != "" {
+		if segments, err = renameSegments(srcSegments, newName, isDir); err != nil {
+			return Relocation{}, err
+		}
+	}
+
+	dstBase, err := baseFn(loc.kind, srcBase, segments)
+	if err != nil {
+		return Relocation{}, err
+	}
+	return Relocation{
+		Src:   filepath.Join(srcBase, filepath.Join(srcSegments...)),
+		Dst:   filepath.Join(dstBase, filepath.Join(segments...)),
+		IsDir: isDir,
+	}, nil
+}
+
+// relocateBase returns the base directory a file of the given kind maps to in
+// the target scope.
+func relocateBase(kind locationKind, scope models.Scope, projectRoot string, segments []string) (string, error) {
+	switch kind {
+	case locGlobal, locProject:
+		return ClaudeDir(scope, projectRoot) + string(os.PathSeparator), nil
+	case locProjectData:
+		if scope != models.ScopeProject {
+			return "", fmt.Errorf("project memory files can only be placed in a project")
+		}
+		return ProjectDataDir(projectRoot) + string(os.PathSeparator), nil
+	case locProjectRoot:
+		if scope == models.ScopeProject {
+			return projectRoot + string(os.PathSeparator), nil
+		}
+		// The global equivalent of a project CLAUDE.md is ~/.claude/CLAUDE.md.
+		if len(segments) == 1 && strings.EqualFold(segments[0], "claude.md") {
+			return GlobalClaudeDir() + string(os.PathSeparator), nil
+		}
+		return "", fmt.Errorf("%s has no global equivalent", segments[len(segments)-1])
+	}
+	return "", fmt.Errorf("unsupported location")
+}
+
+// renameSegments applies newName to the relative path segments of a file.
+func renameSegments(segments []string, newName string, isDir bool) ([]string, error) {
+	out := append([]string(nil), segments...)
+	last := len(out) - 1
+
+	if !isDir && out[0] == "commands" {
+		parts := strings.FieldsFunc(strings.TrimSuffix(newName, ".md"), func(r rune) bool {
+			return r == '/' || r == ':'
+		})
+		if len(parts) == 0 {
+			return nil, fmt.Errorf("invalid name %q", newName)
+		}
+		for _, p := range parts {
+			if !validName.MatchString(p) {
+				return nil, fmt.Errorf("invalid name %q", newName)
+			}
+		}
+		parts[len(parts)-1] += ".md"
+		return append([]string{"commands"}, parts...), nil
+	}
+
+	if !validName.MatchString(newName) {
+		return nil, fmt.Errorf("invalid name %q", newName)
+	}
+	if !isDir && filepath.Ext(newName) == "" {
+		newName += filepath.Ext(out[last])
+	}
+	out[last] = newName
+	return out, nil
+}
+
+// EntriesUnder returns FileEntries for every Claude file below dir, as a
+// scan would report them.
+func EntriesUnder(dir string) ([]models.FileEntry, error) {
+	var entries []models.FileEntry
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !isClaudeFile(path, info.Name()) {
+			return nil
+		}
+		absPath, _ := filepath.Abs(path)
+		entries = append(entries, newEntry(absPath, info))
+		return nil
+	})
+	return entries, err
+}
@@ -1,12 +1,15 @@
 package scanner
 
 import (
+	"context"
 	"crypto/sha256"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
@@ -14,12 +17,24 @@ import (
 
 // Scanner discovers Claude-related files on the filesystem.
 type Scanner struct {
-	rootPath string
+	roots  []Root
+	config Config
+	rules  *rules
+
+	// mu serialises scans, which read and replace the cache.
+	mu        sync.Mutex
+	cachePath string
+	cache     *scanCache
 }
 
-// New creates a scanner. If rootPath is empty, it scans well-known locations.
-func New(rootPath string) *Scanner {
-	return &Scanner{rootPath: rootPath}
+// New creates a scanner for the given roots. Without roots it scans
+// well-known locations and the extra roots of cfg.
+func New(roots []Root, cfg Config) (*Scanner, error) {
+	r, err := cfg.compile(roots)
+	if err != nil {
+		return nil, err
+	}
+	return &Scanner{roots: roots, config: cfg, rules: r, cache: newScanCache()}, nil
 }
 
 // normPath normalises a path to forward slashes for consistent string matching
@@ -30,32 +45,73 @@ func normPath(p string) string {
 
 // Scan discovers all Claude-related files and returns a ScanResult.
 func (s *Scanner) Scan() (*models.ScanResult, error) {
-	var files []models.FileEntry
-	globalSeen := make(map[string]bool)
-
-	paths := s.searchPaths()
-	for _, p := range paths {
-		found, err := s.scanPath(p, globalSeen)
-		if err != nil {
-			continue // skip inaccessible paths
-		}
-		files = append(files, found...)
+	return s.ScanContext(context.Background(), nil)
+}
+
+// ScanContext is Scan with cancellation. If progress is not nil it is called
+// periodically from another goroutine with the running totals. A cancelled
+// scan returns ctx.Err() and leaves the cache untouched.
+func (s *Scanner) ScanContext(ctx context.Context, progress func(models.ScanProgress)) (*models.ScanResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w := &walk{ctx: ctx, rules: s.rules, prev: s.cache, next: newScanCache()}
+	w.next.ScannedAt = time.Now()
+	w.next.Rules = s.rules.key
+	roots := s.searchPaths()
+	files := w.run(roots, progress)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.cache = w.next
+	s.saveCache()
+	if progress != nil {
+		p := w.progress()
+		p.Done = true
+		progress(p)
 	}
 
 	return &models.ScanResult{
-		RootPath:   s.rootPath,
+		Roots:      roots,
 		Files:      files,
-		ScannedAt:  time.Now(),
+		ScannedAt:  w.next.ScannedAt,
 		Categories: models.AllCategories(),
 	}, nil
 }
 
-// searchPaths returns the list of directories to scan.
-func (s *Scanner) searchPaths() []string {
-	if s.rootPath != "" {
-		return []string{s.rootPath}
+// searchPaths returns the directories to scan, without duplicates.
+func (s *Scanner) searchPaths() []models.ScanRoot {
+	var roots []models.ScanRoot
+	seen := make(map[string]bool)
+	add := func(r models.ScanRoot) {
+		if !seen[r.Path] {
+			seen[r.Path] = true
+			roots = append(roots, r)
+		}
+	}
+
+	if len(s.roots) > 0 {
+		for _, r := range s.roots {
+			for _, sr := range r.expand("") {
+				add(sr)
+			}
+		}
+		return roots
+	}
+
+	for _, p := range s.defaultPaths() {
+		add(models.ScanRoot{Path: p, Workspace: DefaultWorkspace})
+	}
+	for _, r := range s.config.Roots {
+		for _, sr := range r.expand(DefaultWorkspace) {
+			add(sr)
+		}
 	}
+	return roots
+}
 
+// defaultPaths returns the well-known directories that exist.
+func (s *Scanner) defaultPaths() []string {
 	var paths []string
 	home := homeDir()
 
@@ -108,106 +164,117 @@ func (s *Scanner) searchPaths() []string {
 	return paths
 }
 
-// scanPath scans a single directory for Claude-related files.
-func (s *Scanner) scanPath(root string, seen map[string]bool) ([]models.FileEntry, error) {
-	var files []models.FileEntry
-
-	// Determine if this is the ~/.claude directory itself
+// isClaudeRoot reports whether a search path is a Claude directory itself
+// (~/.claude or %APPDATA%\Claude), which is scanned in full.
+func isClaudeRoot(root string) bool {
 	normRoot := normPath(root)
-	isClaudeDir := strings.HasSuffix(normRoot, ".claude") || strings.HasSuffix(normRoot, ".claude/") ||
+	return strings.HasSuffix(normRoot, ".claude") || strings.HasSuffix(normRoot, ".claude/") ||
 		strings.HasSuffix(strings.ToLower(normRoot), "/claude") // Windows %APPDATA%\Claude
+}
 
-	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil // skip inaccessible
-		}
-
-		// Skip hidden dirs (except .claude), node_modules, .git, etc.
-		if info.IsDir() {
-			base := info.Name()
-			if base == ".git" || base == "node_modules" || base == ".venv" || base == "__pycache__" {
-				return filepath.SkipDir
-			}
-			// If scanning a broad directory, only descend into .claude dirs or one level
-			if !isClaudeDir && base != ".claude" && strings.HasPrefix(base, ".") && base != "." {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
-		// Limit depth for non-.claude directories to avoid deep scanning
-		normP := normPath(path)
-		if !isClaudeDir && !strings.Contains(normP, ".claude") {
-			rel, _ := filepath.Rel(root, path)
-			normRel := normPath(rel)
-			if strings.Count(normRel, "/") > 1 {
-				return nil
-			}
-		}
-
-		if !isClaudeFile(path, info.Name()) {
-			return nil
-		}
+// EntryFor builds the FileEntry for a single file, as a scan would. It is used
+// to reflect files created or restored outside of a full rescan.
+func EntryFor(path string) (models.FileEntry, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return models.FileEntry{}, err
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return models.FileEntry{}, err
+	}
+	if info.IsDir() {
+		return models.FileEntry{}, fmt.Errorf("%s is a directory", path)
+	}
+	return newEntry(absPath, info), nil
+}
 
-		absPath, _ := filepath.Abs(path)
-		if seen[absPath] {
-			return nil
-		}
-		seen[absPath] = true
-
-		cat := categorize(absPath, info.Name())
-		scope, projectName := extractScope(absPath)
-		entry := models.FileEntry{
-			ID:          fileID(absPath),
-			Path:        absPath,
-			RelPath:     relativeDisplay(absPath),
-			Name:        info.Name(),
-			DisplayName: buildDisplayName(absPath, info.Name(), cat, projectName),
-			Category:    cat,
-			Scope:       scope,
-			ProjectName: projectName,
-			Size:        info.Size(),
-			ModTime:     info.ModTime(),
-			ReadOnly:    !isWritable(absPath),
-		}
-		files = append(files, entry)
-		return nil
-	})
+// withScope recomputes the scope and project fields of a cached entry, which
+// depend on which project directories exist rather than on the file itself.
+func withScope(e models.FileEntry) models.FileEntry {
+	e.Scope, e.ProjectName = extractScope(e.Path)
+	e.DisplayName = buildDisplayName(e.Path, e.Name, e.Category, e.ProjectName)
+	e.ProjectPath = ""
+	if e.Scope == models.ScopeProject {
+		e.ProjectPath, _ = ProjectRoot(e.Path)
+	}
+	return e
+}
 
-	return files, err
+// newEntry derives a FileEntry from an absolute path and its file info.
+func newEntry(absPath string, info os.FileInfo) models.FileEntry {
+	cat := categorize(absPath, info.Name())
+	scope, projectName := extractScope(absPath)
+	var command *models.CommandMeta
+	if cat == models.CategoryCommands {
+		command = readCommandMeta(absPath)
+	}
+	var projectPath string
+	if scope == models.ScopeProject {
+		projectPath, _ = ProjectRoot(absPath)
+	}
+	return models.FileEntry{
+		ID:          fileID(absPath),
+		Path:        absPath,
+		RelPath:     relativeDisplay(absPath),
+		Name:        info.Name(),
+		DisplayName: buildDisplayName(absPath, info.Name(), cat, projectName),
+		Category:    cat,
+		Scope:       scope,
+		ProjectName: projectName,
+		ProjectPath: projectPath,
+		Size:        info.Size(),
+		ModTime:     info.ModTime(),
+		ReadOnly:    !isWritable(absPath),
+		Local:       isLocalFile(info.Name()),
+		Command:     command,
+	}
 }
 
-// isClaudeFile returns true if this file is Claude-related.
-func isClaudeFile(path, name string) bool {
-	nameLower := strings.ToLower(name)
+// commandName returns the invocation name of a slash command file:
+// commands/frontend/build.md → "frontend:build".
+func commandName(absPath string) string {
+	np := normPath(absPath)
+	if idx := strings.LastIndex(np, "/commands/"); idx != -1 {
+		np = np[idx+len("/commands/"):]
+	} else {
+		np = filepath.Base(np)
+	}
+	return strings.ReplaceAll(strings.TrimSuffix(np, filepath.Ext(np)), "/", ":")
+}
 
-	// Direct Claude config files
-	if nameLower == "claude.md" || nameLower == ".clauderc" {
-		return true
+// readCommandMeta reads the frontmatter of a slash command file. The file is
+// only read up to maxFrontmatter bytes; unreadable files yield just the name.
+func readCommandMeta(absPath string) *models.CommandMeta {
+	meta := &models.CommandMeta{Name: commandName(absPath)}
+	f, err := os.Open(absPath)
+	if err != nil {
+		return meta
 	}
+	defer f.Close()
+	data, _ := io.ReadAll(io.LimitReader(f, maxFrontmatter))
 
-	// Files inside a .claude (or Claude on Windows) directory
-	np := normPath(path)
-	npLower := strings.ToLower(np)
-	if strings.Contains(np, ".claude/") || strings.Contains(npLower, "/claude/") {
-		ext := strings.ToLower(filepath.Ext(name))
-		switch ext {
-		case ".md", ".json", ".yaml", ".yml", ".txt", ".toml":
-			return true
-		case ".log":
-			// Log files in debug directories
-			return true
-		case ".sh":
-			// Shell scripts (hooks, snapshots)
-			return true
-		}
-		// Also include files with no extension that might be configs
-		if ext == "" && !strings.HasPrefix(name, ".") {
-			return true
-		}
+	fields := parseFrontmatter(data)
+	meta.Description = fields["description"]
+	meta.ArgumentHint = fields["argument-hint"]
+	meta.Model = fields["model"]
+	if tools := fields["allowed-tools"]; tools != "" {
+		meta.AllowedTools = splitList(tools)
 	}
+	return meta
+}
 
-	return false
+// isClaudeFile returns true if this file is Claude-related under the
+// default rules.
+func isClaudeFile(path, name string) bool {
+	return defaultRules.claudeFile(path, name)
+}
+
+// isLocalFile reports whether name is one of the personal variants Claude Code
+// expects to be kept out of version control.
+func isLocalFile(name string) bool {
+	nameLower := strings.ToLower(name)
+	return nameLower == "settings.local.json" || nameLower == "claude.local.md"
 }
 
 // categorize assigns a category based on file path and name.
@@ -221,6 +288,11 @@ func categorize(path, name string) models.Category {
 		return models.CategoryAgents
 	}
 
+	// Slash commands — .md files in .claude/commands/, optionally namespaced in subdirectories
+	if strings.Contains(pathLower, "/commands/") && strings.HasSuffix(nameLower, ".md") {
+		return models.CategoryCommands
+	}
+
 	// Debug logs
 	if strings.Contains(pathLower, "/debug/") {
 		return models.CategoryDebug
@@ -230,12 +302,13 @@ func categorize(path, name string) models.Category {
 	if strings.Contains(pathLower, "/memory/") || nameLower == "memory.md" {
 		return models.CategoryMemory
 	}
-	if nameLower == "claude.md" && !strings.Contains(pathLower, ".claude") {
+	if (nameLower == "claude.md" || nameLower == "claude.local.md") && !strings.Contains(pathLower, ".claude") {
 		return models.CategoryProject
 	}
 
-	// Settings — config files, hook scripts, stats cache
-	if nameLower == "settings.json" || nameLower == ".clauderc" {
+	// Settings — config files, MCP servers, hook scripts, stats cache
+	switch nameLower {
+	case "settings.json", "settings.local.json", ".clauderc", ".mcp.json", ".claude.json":
 		return models.CategorySettings
 	}
 	if strings.HasSuffix(nameLower, ".sh") && strings.Contains(pathLower, "/.claude/") {
@@ -264,7 +337,7 @@ func categorize(path, name string) models.Category {
 	}
 
 	// Project-level files
-	if nameLower == "claude.md" || nameLower == ".clauderc" {
+	if nameLower == "claude.md" || nameLower == "claude.local.md" {
 		return models.CategoryProject
 	}
 
@@ -273,12 +346,18 @@ func categorize(path, name string) models.Category {
 
 // extractScope determines if a file is global or project-scoped and extracts the project name.
 // Claude encodes project paths like: ~/.claude/projects/-home-user-Projects-MyApp/memory/MEMORY.md
-// The directory name after "projects/" is the encoded project path with "/" replaced by "-".
+// The directory name after "projects/" is the encoded project path with "/" replaced by "-";
+// it is resolved against the filesystem by ProjectFromEncoded.
 func extractScope(absPath string) (models.Scope, string) {
 	np := normPath(absPath)
 
 	home := normPath(homeDir())
 
+	// ~/.claude.json holds user-level configuration despite living outside ~/.claude/
+	if np == home+"/.claude.json" {
+		return models.ScopeGlobal, ""
+	}
+
 	// Look for /projects/ in the path which indicates project-scoped files
 	idx := strings.Index(np, "/.claude/projects/")
 	if idx == -1 {
@@ -303,11 +382,13 @@ func extractScope(absPath string) (models.Scope, string) {
 	}
 
 	encoded := parts[0] // e.g. "-home-user-Projects-MyApp" or "-C-Users-john-Projects-MyApp"
-	return models.ScopeProject, decodeProjectName(encoded)
+	name, _ := ProjectFromEncoded(encoded)
+	return models.ScopeProject, name
 }
 
 // decodeProjectName extracts a human-readable project name from the encoded directory.
 // "-home-user-Documents-Projects-VulWall-Landing" → "VulWall-Landing"
+// It is a fallback for projects whose directory no longer exists; see DecodeProjectPath.
 func decodeProjectName(encoded string) string {
 	// Replace leading dash, then split by common path separators
 	cleaned := strings.TrimPrefix(encoded, "-")
@@ -390,8 +471,30 @@ func buildDisplayName(absPath, name string, cat models.Category, projectName str
 		}
 		return "Project Config"
 
-	case nameLower == "settings.json" && strings.Contains(np, "/.claude/"):
-		return "Global Settings"
+	case nameLower == "claude.local.md" && !strings.Contains(np, "/.claude/"):
+		if projectName != "" {
+			return projectName + " Local Project Config"
+		}
+		return "Local Project Config"
+
+	case (nameLower == "settings.json" || nameLower == "settings.local.json") && strings.Contains(np, "/.claude/"):
+		label := "Settings"
+		if nameLower == "settings.local.json" {
+			label = "Local Settings"
+		}
+		if projectName != "" {
+			return projectName + " " + label
+		}
+		return "Global " + label
+
+	case nameLower == ".mcp.json":
+		if projectName != "" {
+			return projectName + " MCP Servers"
+		}
+		return "MCP Servers"
+
+	case nameLower == ".claude.json":
+		return "User Config (~/.claude.json)"
 
 	case nameLower == ".clauderc":
 		if projectName != "" {
@@ -426,6 +529,14 @@ func buildDisplayName(absPath, name string, cat models.Category, projectName str
 		}
 		return label + " Agent"
 
+	case cat == models.CategoryCommands:
+		// commands/frontend/build.md → "/frontend:build"
+		label := "/" + commandName(absPath)
+		if projectName != "" {
+			return projectName + " — " + label
+		}
+		return label
+
 	case cat == models.CategoryTodos:
 		if projectName != "" {
 			return projectName + " Todos"
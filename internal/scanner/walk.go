@@ -0,0 +1,267 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+)
+
+// scanWorkers bounds how many directories are read concurrently. Scanning is
+// dominated by filesystem latency, so more workers than CPUs pay off.
+var scanWorkers = min(max(4, 2*runtime.NumCPU()), 32)
+
+// progressInterval is how often a running scan reports progress.
+const progressInterval = 200 * time.Millisecond
+
+// walk is the state of one scan: the previous cache, the one replacing it,
+// and the queue of directories still to be read.
+type walk struct {
+	ctx   context.Context
+	rules *rules
+	prev  *scanCache
+
+	mu   sync.Mutex // guards next
+	next *scanCache
+
+	queue dirQueue
+	dirs  atomic.Int64 // directories read
+	found atomic.Int64 // distinct files matched
+}
+
+// dirNode collects what a scan found in one directory. Nodes form a tree
+// mirroring the directories, so results can be put in a deterministic order
+// however the workers interleaved.
+type dirNode struct {
+	files    []models.FileEntry
+	children []*dirNode
+}
+
+// dirTask is a directory waiting to be read, below the search path root
+// and within the discovered repository repo, if any.
+type dirTask struct {
+	root       string
+	claudeRoot bool
+	repo       string
+	path       string
+	node       *dirNode
+}
+
+// run reads the trees of roots with a pool of workers and returns the files
+// found, ordered by search path and then depth-first by name. Files reached
+// through more than one search path are listed once, tagged with the first.
+func (w *walk) run(roots []models.ScanRoot, progress func(models.ScanProgress)) []models.FileEntry {
+	w.queue.init()
+	nodes := make([]*dirNode, len(roots))
+	for i, r := range roots {
+		nodes[i] = &dirNode{}
+		root := r.Path
+		claudeRoot := isClaudeRoot(root)
+		if w.rules.skipDir(root, root, claudeRoot) {
+			continue
+		}
+		w.queue.push(dirTask{root, claudeRoot, "", root, nodes[i]})
+	}
+
+	var workers sync.WaitGroup
+	for i := 0; i < scanWorkers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for {
+				t, ok := w.queue.pop()
+				if !ok {
+					return
+				}
+				if w.ctx.Err() == nil {
+					w.dir(t)
+				}
+				w.queue.done()
+			}
+		}()
+	}
+
+	if progress != nil {
+		stop := make(chan struct{})
+		defer close(stop)
+		go func() {
+			ticker := time.NewTicker(progressInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-stop:
+					return
+				case <-ticker.C:
+					progress(w.progress())
+				}
+			}
+		}()
+	}
+	workers.Wait()
+
+	var files []models.FileEntry
+	seen := make(map[string]bool)
+	var collect func(n *dirNode, root models.ScanRoot)
+	collect = func(n *dirNode, root models.ScanRoot) {
+		for _, f := range n.files {
+			if !seen[f.Path] {
+				seen[f.Path] = true
+				f.Root, f.Workspace = root.Path, root.Workspace
+				files = append(files, f)
+			}
+		}
+		for _, c := range n.children {
+			collect(c, root)
+		}
+	}
+	for i, n := range nodes {
+		collect(n, roots[i])
+	}
+	return files
+}
+
+func (w *walk) progress() models.ScanProgress {
+	return models.ScanProgress{Dirs: w.dirs.Load(), Files: w.found.Load()}
+}
+
+// dir reads one directory, records its Claude files and queues the
+// subdirectories the rules allow.
+func (w *walk) dir(t dirTask) {
+	info, err := os.Lstat(t.path)
+	if err != nil || !info.IsDir() {
+		return // skip inaccessible
+	}
+	listing, ok := w.listing(t.path, info.ModTime())
+	if !ok {
+		return
+	}
+	w.dirs.Add(1)
+	if t.repo == "" && listing.Repo && w.rules.discover && !t.claudeRoot {
+		t.repo = t.path
+	}
+
+	for _, name := range listing.Files {
+		p := filepath.Join(t.path, name)
+		if w.rules.tooDeep(t.root, t.repo, p, t.claudeRoot) || w.rules.excluded(t.root, p, false) {
+			continue
+		}
+		fi, err := os.Lstat(p)
+		if err != nil || fi.IsDir() {
+			continue
+		}
+		t.node.files = append(t.node.files, w.entry(p, fi))
+	}
+	for _, name := range listing.Dirs {
+		p := filepath.Join(t.path, name)
+		if w.rules.skipDir(t.root, p, t.claudeRoot) {
+			continue
+		}
+		child := &dirNode{}
+		t.node.children = append(t.node.children, child)
+		w.queue.push(dirTask{t.root, t.claudeRoot, t.repo, p, child})
+	}
+}
+
+// listing returns the cached listing of a directory if it is still valid,
+// and reads the directory otherwise.
+func (w *walk) listing(path string, modTime time.Time) (cachedDir, bool) {
+	w.mu.Lock()
+	listing, ok := w.next.Dirs[path] // already read under another search path
+	w.mu.Unlock()
+	if ok {
+		return listing, true
+	}
+
+	listing, ok = w.prev.Dirs[path]
+	if !ok || !w.prev.trusted(listing.ModTime, modTime) {
+		var err error
+		if listing, err = listDir(path, modTime, w.rules); err != nil {
+			return cachedDir{}, false
+		}
+	}
+	w.mu.Lock()
+	w.next.Dirs[path] = listing
+	w.mu.Unlock()
+	return listing, true
+}
+
+// entry returns the FileEntry for a file, reusing the one built under
+// another search path or, if the file is unchanged, the cached one. Fields
+// of cached entries that depend on other directories are recomputed.
+func (w *walk) entry(path string, info os.FileInfo) models.FileEntry {
+	w.mu.Lock()
+	c, ok := w.next.Files[path]
+	w.mu.Unlock()
+	if ok {
+		return c.Entry
+	}
+
+	c, ok = w.prev.Files[path]
+	if ok && c.Entry.Size == info.Size() && c.Mode == info.Mode() && w.prev.trusted(c.Entry.ModTime, info.ModTime()) {
+		c.Entry = withScope(c.Entry)
+	} else {
+		c = cachedFile{Entry: newEntry(path, info), Mode: info.Mode()}
+	}
+	w.mu.Lock()
+	if _, dup := w.next.Files[path]; !dup {
+		w.next.Files[path] = c
+		w.found.Add(1)
+	}
+	w.mu.Unlock()
+	return c.Entry
+}
+
+// dirQueue is an unbounded work queue that knows when all work is done:
+// pop blocks while the queue is empty but tasks are still being processed,
+// since those may queue more.
+type dirQueue struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	tasks   []dirTask
+	pending int // queued or in progress
+}
+
+func (q *dirQueue) init() {
+	q.cond = sync.NewCond(&q.mu)
+}
+
+func (q *dirQueue) push(t dirTask) {
+	q.mu.Lock()
+	q.tasks = append(q.tasks, t)
+	q.pending++
+	q.mu.Unlock()
+	q.cond.Signal()
+}
+
+// pop returns the most recently queued task, which keeps the queue short by
+// finishing subtrees before starting new ones. It returns false once all
+// work is done.
+func (q *dirQueue) pop() (dirTask, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.tasks) == 0 && q.pending > 0 {
+		q.cond.Wait()
+	}
+	if len(q.tasks) == 0 {
+		return dirTask{}, false
+	}
+	t := q.tasks[len(q.tasks)-1]
+	q.tasks = q.tasks[:len(q.tasks)-1]
+	return t, true
+}
+
+// done marks a popped task as finished.
+func (q *dirQueue) done() {
+	q.mu.Lock()
+	q.pending--
+	finished := q.pending == 0
+	q.mu.Unlock()
+	if finished {
+		q.cond.Broadcast()
+	}
+}
@@ -0,0 +1,193 @@
+package scanner
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+)
+
+// Matches reports whether a scan would include the file at path.
+func (s *Scanner) Matches(path string) bool {
+	for _, root := range s.searchPaths() {
+		if s.matchesIn(root.Path, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesUnder returns the entries of the files below dir that a scan would
+// include, tagged with their search path and workspace. Like a scan, it does
+// not enter the directories the rules skip.
+func (s *Scanner) MatchesUnder(dir string) ([]models.FileEntry, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var entries []models.FileEntry
+	for _, root := range s.searchPaths() {
+		if !s.inRoot(root.Path, dir) {
+			continue
+		}
+		claudeRoot := isClaudeRoot(root.Path)
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			switch {
+			case err != nil:
+				if path == dir {
+					return err
+				}
+				return nil
+			case d.IsDir():
+				if path != dir && s.rules.skipDir(root.Path, path, claudeRoot) {
+					return filepath.SkipDir
+				}
+				return nil
+			case seen[path] || !s.matchesIn(root.Path, path):
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			seen[path] = true
+			e := newEntry(path, info)
+			e.Root, e.Workspace = root.Path, root.Workspace
+			entries = append(entries, e)
+			return nil
+		})
+		if err != nil {
+			return entries, err
+		}
+	}
+	return entries, nil
+}
+
+// WatchDirs returns the existing directories whose entries can change the
+// scan result: those a scan enters, such as the search paths, the
+// directories within the depth limit below them and any .claude directory.
+func (s *Scanner) WatchDirs() []string {
+	seen := make(map[string]bool)
+	var dirs []string
+	for _, root := range s.searchPaths() {
+		dirs = s.appendWatchDirs(dirs, root.Path, root.Path, seen)
+	}
+	return dirs
+}
+
+// WatchDirsUnder is WatchDirs limited to dir and its subdirectories, e.g.
+// for a directory that was just created.
+func (s *Scanner) WatchDirsUnder(dir string) []string {
+	seen := make(map[string]bool)
+	var dirs []string
+	for _, root := range s.searchPaths() {
+		if s.inRoot(root.Path, dir) {
+			dirs = s.appendWatchDirs(dirs, root.Path, dir, seen)
+		}
+	}
+	return dirs
+}
+
+// appendWatchDirs walks start, which lies within the search path root, and
+// appends the directories to watch.
+func (s *Scanner) appendWatchDirs(dirs []string, root, start string, seen map[string]bool) []string {
+	claudeRoot := isClaudeRoot(root)
+	filepath.WalkDir(start, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if s.rules.skipDir(root, path, claudeRoot) {
+			return filepath.SkipDir
+		}
+		if !seen[path] {
+			seen[path] = true
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	return dirs
+}
+
+// Tag sets the search path and workspace of an entry built outside a scan,
+// such as by EntryFor: the first search path whose scan would include the
+// file, else the first one containing it.
+func (s *Scanner) Tag(e *models.FileEntry) {
+	roots := s.searchPaths()
+	for _, root := range roots {
+		if s.matchesIn(root.Path, e.Path) {
+			e.Root, e.Workspace = root.Path, root.Workspace
+			return
+		}
+	}
+	for _, root := range roots {
+		if s.inRoot(root.Path, filepath.Dir(e.Path)) {
+			e.Root, e.Workspace = root.Path, root.Workspace
+			return
+		}
+	}
+}
+
+// matchesIn applies the scan rules to a single file below root.
+func (s *Scanner) matchesIn(root, path string) bool {
+	return s.inRoot(root, filepath.Dir(path)) &&
+		!s.rules.tooDeep(root, s.repoOf(root, filepath.Dir(path)), path, isClaudeRoot(root)) &&
+		!s.rules.excluded(root, path, false) &&
+		s.rules.claudeFile(path, filepath.Base(path))
+}
+
+// inRoot reports whether dir is root or below it without passing through a
+// directory the scan skips.
+func (s *Scanner) inRoot(root, dir string) bool {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	claudeRoot := isClaudeRoot(root)
+	if s.rules.skipDir(root, root, claudeRoot) {
+		return false
+	}
+	if rel != "." {
+		p := root
+		for _, name := range strings.Split(normPath(rel), "/") {
+			p = filepath.Join(p, name)
+			if s.rules.skipDir(root, p, claudeRoot) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// repoOf returns the outermost git repository below root that contains dir,
+// as the scan would discover it, or "" if there is none.
+func (s *Scanner) repoOf(root, dir string) string {
+	if !s.rules.discover || isClaudeRoot(root) {
+		return ""
+	}
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return ""
+	}
+	p := root
+	if isRepo(p) {
+		return p
+	}
+	if rel != "." {
+		for _, name := range strings.Split(normPath(rel), "/") {
+			p = filepath.Join(p, name)
+			if isRepo(p) {
+				return p
+			}
+		}
+	}
+	return ""
+}
+
+// isRepo reports whether dir is the root of a git repository.
+func isRepo(dir string) bool {
+	_, err := os.Lstat(filepath.Join(dir, ".git"))
+	return err == nil
+}
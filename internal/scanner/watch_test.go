@@ -0,0 +1,56 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestMatchesUnder(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	work := filepath.Join(home, "work")
+	for _, f := range []string{
+		"app/CLAUDE.md",
+		"app/.claude/settings.json",
+		"app/node_modules/pkg/CLAUDE.md",
+		"app/.cache/CLAUDE.md",
+		"app/build/CLAUDE.md",
+		"app/sub/CLAUDE.md",
+		"app/notes.md",
+	} {
+		path := filepath.Join(work, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	one := 1
+	sc, err := New([]Root{{Name: "w", Path: work}}, Config{MaxDepth: &one, Excludes: []string{"build/"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := sc.MatchesUnder(filepath.Join(work, "app"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		rel, _ := filepath.Rel(work, e.Path)
+		got = append(got, filepath.ToSlash(rel))
+		if e.Root != work || e.Workspace != "w" {
+			t.Errorf("%s tagged %q/%q, want %q/%q", rel, e.Root, e.Workspace, work, "w")
+		}
+	}
+	sort.Strings(got)
+	want := []string{"app/.claude/settings.json", "app/CLAUDE.md"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MatchesUnder found %v, want %v", got, want)
+	}
+}
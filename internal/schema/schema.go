@@ -0,0 +1,150 @@
+// Package schema validates Claude's JSON configuration files against
+// embedded JSON Schemas before they are saved.
+//
+// Only the subset of JSON Schema used by the embedded schemas is supported:
+// type, enum, properties, required, additionalProperties, items, minimum,
+// pattern, if/then, and local $ref into "definitions".
+package schema
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+//go:embed schemas/*.json
+var schemaFS embed.FS
+
+// Names of the embedded schemas, as reported by For.
+const (
+	Settings   = "settings"    // settings.json, settings.local.json, managed-settings.json
+	MCP        = "mcp"         // project .mcp.json
+	ClaudeJSON = "claude-json" // ~/.claude.json
+)
+
+var schemas = map[string]*node{
+	Settings:   mustLoad("schemas/settings.json"),
+	MCP:        mustLoad("schemas/mcp.json"),
+	ClaudeJSON: mustLoad("schemas/claude-json.json"),
+}
+
+// node is one (sub)schema.
+type node struct {
+	Type                 typeList         `json:"type"`
+	Enum                 []interface{}    `json:"enum"`
+	Properties           map[string]*node `json:"properties"`
+	Required             []string         `json:"required"`
+	AdditionalProperties json.RawMessage  `json:"additionalProperties"`
+	Items                *node            `json:"items"`
+	Minimum              *float64         `json:"minimum"`
+	Pattern              string           `json:"pattern"`
+	If                   *node            `json:"if"`
+	Then                 *node            `json:"then"`
+	Ref                  string           `json:"$ref"`
+	Definitions          map[string]*node `json:"definitions"`
+
+	// Resolved by compile.
+	noAdditional bool
+	additional   *node
+	pattern      *regexp.Regexp
+	ref          *node
+}
+
+// typeList accepts both "type": "string" and "type": ["string", "null"].
+type typeList []string
+
+func (t *typeList) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*t = typeList{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*t = many
+	return nil
+}
+
+func mustLoad(name string) *node {
+	data, err := schemaFS.ReadFile(name)
+	if err != nil {
+		panic(err)
+	}
+	var root node
+	if err := json.Unmarshal(data, &root); err != nil {
+		panic(fmt.Sprintf("schema %s: %v", name, err))
+	}
+	if err := root.compile(&root); err != nil {
+		panic(fmt.Sprintf("schema %s: %v", name, err))
+	}
+	return &root
+}
+
+// compile resolves references, patterns and additionalProperties below n.
+func (n *node) compile(root *node) error {
+	if n.Ref != "" {
+		name := strings.TrimPrefix(n.Ref, "#/definitions/")
+		if n.ref = root.Definitions[name]; n.ref == nil || name == n.Ref {
+			return fmt.Errorf("unresolvable $ref %q", n.Ref)
+		}
+	}
+	if n.Pattern != "" {
+		re, err := regexp.Compile(n.Pattern)
+		if err != nil {
+			return err
+		}
+		n.pattern = re
+	}
+	if len(n.AdditionalProperties) > 0 {
+		var allowed bool
+		if err := json.Unmarshal(n.AdditionalProperties, &allowed); err == nil {
+			n.noAdditional = !allowed
+		} else {
+			n.additional = new(node)
+			if err := json.Unmarshal(n.AdditionalProperties, n.additional); err != nil {
+				return err
+			}
+		}
+	}
+
+	children := []*node{n.Items, n.additional, n.If, n.Then}
+	for _, c := range n.Properties {
+		children = append(children, c)
+	}
+	for _, c := range n.Definitions {
+		children = append(children, c)
+	}
+	for _, c := range children {
+		if c == nil {
+			continue
+		}
+		if err := c.compile(root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// For returns the name of the schema that applies to path, or "" if the file
+// has no schema. Other .json files are still checked for syntax by Validate.
+func For(path string) string {
+	base := strings.ToLower(filepath.Base(path))
+	switch base {
+	case "settings.local.json", "managed-settings.json":
+		return Settings
+	case "settings.json":
+		if filepath.Base(filepath.Dir(path)) == ".claude" {
+			return Settings
+		}
+	case ".mcp.json":
+		return MCP
+	case ".claude.json":
+		return ClaudeJSON
+	}
+	return ""
+}
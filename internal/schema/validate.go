@@ -0,0 +1,294 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+)
+
+// Validate checks content as the file at path. Any .json file is checked for
+// syntax; files with a known schema (see For) are also checked against it.
+// It returns the schema name used and the problems found, ordered by
+// position. Files that are not JSON yield no errors.
+func Validate(path string, content []byte) (string, []models.ValidationError) {
+	name := For(path)
+	if name == "" && !strings.EqualFold(filepath.Ext(path), ".json") {
+		return "", nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return name, []models.ValidationError{syntaxError(content, err)}
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		off := int(dec.InputOffset())
+		return name, []models.ValidationError{at(content, "", skipSpace(content, off), "unexpected data after the top-level value")}
+	}
+	if name == "" {
+		return "", nil
+	}
+
+	v := validator{offsets: offsets(content)}
+	v.check(schemas[name], value, "")
+	sort.SliceStable(v.errs, func(i, j int) bool { return v.errs[i].off < v.errs[j].off })
+
+	errs := make([]models.ValidationError, len(v.errs))
+	for i, e := range v.errs {
+		errs[i] = at(content, e.pointer, e.off, e.message)
+	}
+	return name, errs
+}
+
+// syntaxError converts a decoding error into a positioned ValidationError.
+func syntaxError(content []byte, err error) models.ValidationError {
+	var se *json.SyntaxError
+	switch {
+	case errors.As(err, &se):
+		// Offset counts the bytes read including the offending one.
+		return at(content, "", int(se.Offset)-1, se.Error())
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return at(content, "", len(content), "unexpected end of JSON input")
+	}
+	return at(content, "", 0, err.Error())
+}
+
+// at builds a ValidationError for the byte offset off in content.
+func at(content []byte, pointer string, off int, message string) models.ValidationError {
+	off = max(0, min(off, len(content)))
+	line, col := 1, 1
+	lineStart := 0
+	for i := 0; i < off; i++ {
+		if content[i] == '\n' {
+			line++
+			lineStart = i + 1
+		}
+	}
+	col += utf8.RuneCount(content[lineStart:off])
+	return models.ValidationError{Pointer: pointer, Line: line, Column: col, Message: message}
+}
+
+type pendingError struct {
+	pointer string
+	off     int
+	message string
+}
+
+type validator struct {
+	offsets map[string]int // JSON pointer → byte offset of the value
+	errs    []pendingError
+}
+
+func (v *validator) fail(pointer, format string, args ...interface{}) {
+	v.errs = append(v.errs, pendingError{pointer, v.offsets[pointer], fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) check(n *node, value interface{}, pointer string) {
+	for n.ref != nil {
+		n = n.ref
+	}
+
+	if len(n.Type) > 0 && !hasType(n.Type, value) {
+		v.fail(pointer, "expected %s, got %s", strings.Join(n.Type, " or "), typeOf(value))
+		return
+	}
+	if len(n.Enum) > 0 && !inEnum(n.Enum, value) {
+		opts := make([]string, len(n.Enum))
+		for i, e := range n.Enum {
+			b, _ := json.Marshal(e)
+			opts[i] = string(b)
+		}
+		v.fail(pointer, "must be one of %s", strings.Join(opts, ", "))
+		return
+	}
+
+	if n.If != nil && n.Then != nil && v.matches(n.If, value) {
+		v.check(n.Then, value, pointer)
+	}
+
+	switch val := value.(type) {
+	case map[string]interface{}:
+		for _, req := range n.Required {
+			if _, ok := val[req]; !ok {
+				v.fail(pointer, "missing required property %q", req)
+			}
+		}
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			child := pointer + "/" + escapePointer(k)
+			switch sub, ok := n.Properties[k]; {
+			case ok:
+				v.check(sub, val[k], child)
+			case n.additional != nil:
+				v.check(n.additional, val[k], child)
+			case n.noAdditional:
+				v.fail(child, "unknown property %q", k)
+			}
+		}
+
+	case []interface{}:
+		if n.Items != nil {
+			for i, item := range val {
+				v.check(n.Items, item, pointer+"/"+strconv.Itoa(i))
+			}
+		}
+
+	case json.Number:
+		if n.Minimum != nil {
+			if f, err := val.Float64(); err == nil && f < *n.Minimum {
+				v.fail(pointer, "must be at least %v", *n.Minimum)
+			}
+		}
+
+	case string:
+		if n.pattern != nil && !n.pattern.MatchString(val) {
+			v.fail(pointer, "%q does not match the expected format %s", val, n.Pattern)
+		}
+	}
+}
+
+// matches reports whether value is valid against n, without recording errors.
+func (v *validator) matches(n *node, value interface{}) bool {
+	sub := validator{offsets: v.offsets}
+	sub.check(n, value, "")
+	return len(sub.errs) == 0
+}
+
+func typeOf(value interface{}) string {
+	switch val := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if isInteger(val) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func hasType(types []string, value interface{}) bool {
+	actual := typeOf(value)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func isInteger(n json.Number) bool {
+	if _, err := n.Int64(); err == nil {
+		return true
+	}
+	f, err := n.Float64()
+	return err == nil && f == math.Trunc(f)
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, e := range enum {
+		switch ev := e.(type) {
+		case float64:
+			if n, ok := value.(json.Number); ok {
+				if f, err := n.Float64(); err == nil && f == ev {
+					return true
+				}
+			}
+		default:
+			if e == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// escapePointer escapes a key for use as an RFC 6901 reference token.
+func escapePointer(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
+
+// offsets maps the JSON pointer of every value in content to the byte offset
+// where that value starts. content must be valid JSON.
+func offsets(content []byte) map[string]int {
+	type frame struct {
+		pointer string
+		object  bool
+		key     string
+		haveKey bool
+		index   int
+	}
+	out := make(map[string]int)
+	var stack []*frame
+
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.UseNumber()
+	for {
+		start := skipSpace(content, int(dec.InputOffset()))
+		tok, err := dec.Token()
+		if err != nil {
+			return out
+		}
+		if d, ok := tok.(json.Delim); ok && (d == '}' || d == ']') {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		pointer := ""
+		if len(stack) > 0 {
+			top := stack[len(stack)-1]
+			if top.object {
+				if !top.haveKey {
+					top.key, top.haveKey = tok.(string), true
+					continue
+				}
+				pointer = top.pointer + "/" + escapePointer(top.key)
+				top.haveKey = false
+			} else {
+				pointer = top.pointer + "/" + strconv.Itoa(top.index)
+				top.index++
+			}
+		}
+		out[pointer] = start
+		if d, ok := tok.(json.Delim); ok {
+			stack = append(stack, &frame{pointer: pointer, object: d == '{'})
+		}
+	}
+}
+
+// skipSpace advances off past whitespace and the ':' and ',' separators,
+// which the decoder consumes as part of the following token.
+func skipSpace(content []byte, off int) int {
+	for off < len(content) {
+		switch content[off] {
+		case ' ', '\t', '\r', '\n', ':', ',':
+			off++
+		default:
+			return off
+		}
+	}
+	return off
+}
@@ -0,0 +1,179 @@
+package search
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+)
+
+// maxIndexSize caps the size of files loaded into the index. Larger files
+// (typically debug logs) are skipped to keep memory usage predictable.
+const maxIndexSize = 2 << 20
+
+// document is the indexed content of a single file.
+type document struct {
+	lines []string
+}
+
+// Index is an in-memory inverted index over file contents.
+// It maps lowercase tokens to the files and lines they appear on.
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[string]*document
+	postings map[string]map[string][]int // token → file ID → sorted line numbers (0-based)
+	tokens   []string                    // sorted token list for prefix lookups
+}
+
+// New creates an empty index.
+func New() *Index {
+	return &Index{
+		docs:     make(map[string]*document),
+		postings: make(map[string]map[string][]int),
+	}
+}
+
+// Rebuild discards the current index and indexes every file from disk.
+// Files that are too large, unreadable or binary are skipped.
+func (ix *Index) Rebuild(files []models.FileEntry) {
+	fresh := New()
+	for _, f := range files {
+		if f.Size > maxIndexSize {
+			continue
+		}
+		data, err := os.ReadFile(f.Path)
+		if err != nil || !utf8.Valid(data) {
+			continue
+		}
+		fresh.add(f.ID, string(data))
+	}
+	fresh.tokens = make([]string, 0, len(fresh.postings))
+	for tok := range fresh.postings {
+		fresh.tokens = append(fresh.tokens, tok)
+	}
+	sort.Strings(fresh.tokens)
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.docs = fresh.docs
+	ix.postings = fresh.postings
+	ix.tokens = fresh.tokens
+}
+
+// Update replaces the indexed content of a single file.
+func (ix *Index) Update(id, content string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	dropped := ix.remove(id, nil)
+	var added []string
+	if len(content) <= maxIndexSize && utf8.ValidString(content) {
+		added = ix.add(id, content)
+	}
+	ix.updateTokens(added, dropped)
+}
+
+// Remove drops files from the index.
+func (ix *Index) Remove(ids ...string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	var dropped map[string]bool
+	for _, id := range ids {
+		dropped = ix.remove(id, dropped)
+	}
+	ix.updateTokens(nil, dropped)
+}
+
+// add indexes content under id and returns the tokens it added to the
+// index. Must hold mu (or own ix exclusively).
+func (ix *Index) add(id, content string) (added []string) {
+	lines := strings.Split(content, "\n")
+	ix.docs[id] = &document{lines: lines}
+	for n, line := range lines {
+		for _, tok := range tokenize(line) {
+			byDoc := ix.postings[tok]
+			if byDoc == nil {
+				byDoc = make(map[string][]int)
+				ix.postings[tok] = byDoc
+				added = append(added, tok)
+			}
+			if l := byDoc[id]; len(l) > 0 && l[len(l)-1] == n {
+				continue
+			}
+			byDoc[id] = append(byDoc[id], n)
+		}
+	}
+	return added
+}
+
+// remove drops id from the index, adding the tokens no longer in the index
+// to dropped, which it returns. Must hold mu.
+func (ix *Index) remove(id string, dropped map[string]bool) map[string]bool {
+	doc, ok := ix.docs[id]
+	if !ok {
+		return dropped
+	}
+	delete(ix.docs, id)
+	for _, line := range doc.lines {
+		for _, tok := range tokenize(line) {
+			byDoc := ix.postings[tok]
+			if byDoc == nil {
+				continue
+			}
+			delete(byDoc, id)
+			if len(byDoc) == 0 {
+				delete(ix.postings, tok)
+				if dropped == nil {
+					dropped = make(map[string]bool)
+				}
+				dropped[tok] = true
+			}
+		}
+	}
+	return dropped
+}
+
+// updateTokens brings the sorted token list in line with the postings after
+// tokens were added and dropped, merging rather than sorting it again. A
+// token may be in both if it was dropped and then added back. Must hold mu.
+func (ix *Index) updateTokens(added []string, dropped map[string]bool) {
+	fresh := added[:0]
+	for _, tok := range added {
+		if dropped[tok] {
+			delete(dropped, tok) // still in ix.tokens
+		} else {
+			fresh = append(fresh, tok)
+		}
+	}
+	if len(fresh) == 0 && len(dropped) == 0 {
+		return
+	}
+	sort.Strings(fresh)
+
+	tokens := make([]string, 0, len(ix.tokens)+len(fresh)-len(dropped))
+	i := 0
+	for _, tok := range ix.tokens {
+		if dropped[tok] {
+			continue
+		}
+		for ; i < len(fresh) && fresh[i] < tok; i++ {
+			tokens = append(tokens, fresh[i])
+		}
+		tokens = append(tokens, tok)
+	}
+	ix.tokens = append(tokens, fresh[i:]...)
+}
+
+// tokenize splits text into lowercase word tokens (letters, digits and underscores).
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !isWordRune(r)
+	})
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
@@ -0,0 +1,161 @@
+package search
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", []string{}},
+		{"Hello, World!", []string{"hello", "world"}},
+		{"snake_case and kebab-case", []string{"snake_case", "and", "kebab", "case"}},
+		{"v1.2 = 3", []string{"v1", "2", "3"}},
+		{"Ünïcode straße", []string{"ünïcode", "straße"}},
+	}
+	for _, tt := range tests {
+		if got := tokenize(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tokenize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// hitIDs runs a text query and returns the IDs of the files found, sorted.
+func hitIDs(t *testing.T, ix *Index, query string) []string {
+	t.Helper()
+	hits, err := ix.Search(query, ModeText)
+	if err != nil {
+		t.Fatalf("Search(%q): %v", query, err)
+	}
+	ids := []string{}
+	for _, h := range hits {
+		ids = append(ids, h.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// checkTokens fails unless the token list is the sorted set of indexed tokens.
+func checkTokens(t *testing.T, ix *Index) {
+	t.Helper()
+	want := make([]string, 0, len(ix.postings))
+	for tok := range ix.postings {
+		want = append(want, tok)
+	}
+	sort.Strings(want)
+	if !reflect.DeepEqual(ix.tokens, want) {
+		t.Errorf("tokens = %q, want %q", ix.tokens, want)
+	}
+}
+
+func TestSearchText(t *testing.T) {
+	ix := New()
+	ix.Update("a", "# Build\nrun go test\nthen deploy")
+	ix.Update("b", "go build only")
+	ix.Update("c", "deployment notes\ntest plan")
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"go", []string{"a", "b"}},
+		{"GO", []string{"a", "b"}},
+		{"go deploy", []string{"a"}},         // terms on different lines
+		{"test deploy", []string{"a"}},       // "deployment" is not "deploy"
+		{"test deploy*", []string{"a", "c"}}, // prefix
+		{`"go test"`, []string{"a"}},
+		{`"test go"`, []string{}},
+		{"missing", []string{}},
+		{"go missing", []string{}},
+	}
+	for _, tt := range tests {
+		if got := hitIDs(t, ix, tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+	if _, err := ix.Search("  ,; ", ModeText); err != ErrEmptyQuery {
+		t.Errorf("Search of punctuation: err = %v, want ErrEmptyQuery", err)
+	}
+}
+
+func TestUpdateRemove(t *testing.T) {
+	ix := New()
+	ix.Update("a", "alpha beta")
+	ix.Update("b", "beta gamma")
+	checkTokens(t, ix)
+
+	ix.Update("a", "alpha delta")
+	checkTokens(t, ix)
+	if got := hitIDs(t, ix, "beta"); !reflect.DeepEqual(got, []string{"b"}) {
+		t.Errorf("after update, beta found in %v", got)
+	}
+	if got := hitIDs(t, ix, "delt*"); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Errorf("after update, delt* found in %v", got)
+	}
+
+	ix.Update("a", string([]byte{0xff, 'x'})) // invalid UTF-8 is not indexed
+	checkTokens(t, ix)
+	if got := hitIDs(t, ix, "alpha"); len(got) != 0 {
+		t.Errorf("binary update left alpha in %v", got)
+	}
+
+	ix.Remove("b", "unknown")
+	checkTokens(t, ix)
+	if len(ix.tokens) != 0 || len(ix.docs) != 0 {
+		t.Errorf("after removing everything: tokens %q, docs %d", ix.tokens, len(ix.docs))
+	}
+}
+
+func TestUpdateManyTokens(t *testing.T) {
+	ix := New()
+	for i := 0; i < 50; i++ {
+		content := ""
+		for j := 0; j < 20; j++ {
+			content += fmt.Sprintf("w%d ", (i*7+j*13)%300)
+		}
+		ix.Update(fmt.Sprint(i%10), content)
+		checkTokens(t, ix)
+	}
+	for i := 0; i < 10; i += 3 {
+		ix.Remove(fmt.Sprint(i))
+		checkTokens(t, ix)
+	}
+}
+
+func TestRebuild(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) models.FileEntry {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return models.FileEntry{ID: name, Path: path, Size: int64(len(content))}
+	}
+	files := []models.FileEntry{
+		write("a.md", "shared alpha"),
+		write("b.md", "shared beta"),
+		write("bin", "shared\x00\xff"),
+		{ID: "big", Path: filepath.Join(dir, "big"), Size: maxIndexSize + 1},
+		{ID: "gone", Path: filepath.Join(dir, "gone")},
+	}
+
+	ix := New()
+	ix.Update("stale", "shared stale")
+	ix.Rebuild(files)
+	checkTokens(t, ix)
+	if got := hitIDs(t, ix, "shared"); !reflect.DeepEqual(got, []string{"a.md", "b.md"}) {
+		t.Errorf("after rebuild, shared found in %v", got)
+	}
+	if got := hitIDs(t, ix, "stale"); len(got) != 0 {
+		t.Errorf("rebuild kept stale file: %v", got)
+	}
+}
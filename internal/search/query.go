@@ -0,0 +1,386 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+)
+
+// Query modes accepted by Search.
+const (
+	ModeText  = "text"  // terms, "quoted phrases" and prefix* terms, all required
+	ModeRegex = "regex" // a single RE2 regular expression matched per line
+)
+
+const (
+	maxMatchesPerFile = 20
+	snippetWidth      = 240 // bytes of context kept around the first highlight
+)
+
+// ErrEmptyQuery is returned when the query has no searchable terms.
+var ErrEmptyQuery = errors.New("empty query")
+
+// Hit is the set of matching lines found in one file.
+type Hit struct {
+	ID         string
+	Matches    []models.SearchMatch
+	MatchCount int
+}
+
+type clauseKind int
+
+const (
+	clauseTerm clauseKind = iota
+	clausePrefix
+	clausePhrase
+)
+
+// clause is one required element of a text query.
+type clause struct {
+	kind  clauseKind
+	words []string // one word for term/prefix, several for a phrase
+}
+
+// wordSpan is a word within a line with its byte offsets.
+type wordSpan struct {
+	start, end int
+	word       string // lowercased
+}
+
+// Search runs a query against the index and returns hits ordered by match count.
+func (ix *Index) Search(query, mode string) ([]Hit, error) {
+	switch mode {
+	case "", ModeText:
+		clauses := parseQuery(query)
+		if len(clauses) == 0 {
+			return nil, ErrEmptyQuery
+		}
+		ix.mu.RLock()
+		defer ix.mu.RUnlock()
+		return ix.searchText(clauses), nil
+	case ModeRegex:
+		if query == "" {
+			return nil, ErrEmptyQuery
+		}
+		re, err := regexp.Compile(query)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex: %w", err)
+		}
+		ix.mu.RLock()
+		defer ix.mu.RUnlock()
+		return ix.searchRegex(re), nil
+	default:
+		return nil, fmt.Errorf("unknown search mode %q", mode)
+	}
+}
+
+// parseQuery splits a text query into clauses. Double-quoted sections become
+// phrases and words ending in "*" become prefix clauses.
+func parseQuery(q string) []clause {
+	var clauses []clause
+	for q != "" {
+		q = strings.TrimSpace(q)
+		if q == "" {
+			break
+		}
+		if q[0] == '"' {
+			end := strings.IndexByte(q[1:], '"')
+			var phrase string
+			if end == -1 {
+				phrase, q = q[1:], ""
+			} else {
+				phrase, q = q[1:end+1], q[end+2:]
+			}
+			if words := tokenize(phrase); len(words) == 1 {
+				clauses = append(clauses, clause{kind: clauseTerm, words: words})
+			} else if len(words) > 1 {
+				clauses = append(clauses, clause{kind: clausePhrase, words: words})
+			}
+			continue
+		}
+
+		word := q
+		if i := strings.IndexAny(q, " \t\""); i != -1 {
+			word, q = q[:i], q[i:]
+		} else {
+			q = ""
+		}
+		toks := tokenize(word)
+		for _, tok := range toks {
+			clauses = append(clauses, clause{kind: clauseTerm, words: []string{tok}})
+		}
+		if strings.HasSuffix(word, "*") && len(toks) > 0 {
+			clauses[len(clauses)-1].kind = clausePrefix
+		}
+	}
+	return clauses
+}
+
+// searchText evaluates clauses using the postings lists. Must hold mu.
+func (ix *Index) searchText(clauses []clause) []Hit {
+	// Candidate lines per clause, narrowed to files matching every clause.
+	var docs map[string]map[int]bool
+	for _, c := range clauses {
+		lines := ix.clauseLines(c)
+		if docs == nil {
+			docs = make(map[string]map[int]bool, len(lines))
+			for id, l := range lines {
+				docs[id] = l
+			}
+			continue
+		}
+		for id, existing := range docs {
+			l, ok := lines[id]
+			if !ok {
+				delete(docs, id)
+				continue
+			}
+			for n := range l {
+				existing[n] = true
+			}
+		}
+	}
+
+	var hits []Hit
+	for id, candidates := range docs {
+		doc := ix.docs[id]
+		nums := make([]int, 0, len(candidates))
+		for n := range candidates {
+			nums = append(nums, n)
+		}
+		sort.Ints(nums)
+
+		hit := Hit{ID: id}
+		for _, n := range nums {
+			spans := matchClauses(doc.lines[n], clauses)
+			if len(spans) == 0 {
+				continue
+			}
+			hit.addMatch(n, doc.lines[n], spans)
+		}
+		if hit.MatchCount > 0 {
+			hits = append(hits, hit)
+		}
+	}
+	sortHits(hits)
+	return hits
+}
+
+// clauseLines returns, per file, the lines that may satisfy a clause. Must hold mu.
+func (ix *Index) clauseLines(c clause) map[string]map[int]bool {
+	out := make(map[string]map[int]bool)
+	collect := func(tok string) {
+		for id, lines := range ix.postings[tok] {
+			set := out[id]
+			if set == nil {
+				set = make(map[int]bool, len(lines))
+				out[id] = set
+			}
+			for _, n := range lines {
+				set[n] = true
+			}
+		}
+	}
+
+	switch c.kind {
+	case clauseTerm:
+		collect(c.words[0])
+	case clausePrefix:
+		i := sort.SearchStrings(ix.tokens, c.words[0])
+		for ; i < len(ix.tokens) && strings.HasPrefix(ix.tokens[i], c.words[0]); i++ {
+			collect(ix.tokens[i])
+		}
+	case clausePhrase:
+		// A phrase can only match on lines containing every one of its words.
+		collect(c.words[0])
+		for _, w := range c.words[1:] {
+			byDoc := ix.postings[w]
+			for id, set := range out {
+				keep := make(map[int]bool)
+				for _, n := range byDoc[id] {
+					if set[n] {
+						keep[n] = true
+					}
+				}
+				if len(keep) == 0 {
+					delete(out, id)
+				} else {
+					out[id] = keep
+				}
+			}
+		}
+	}
+	return out
+}
+
+// matchClauses returns the byte ranges in line matched by any clause.
+func matchClauses(line string, clauses []clause) [][2]int {
+	words := splitWords(line)
+	var spans [][2]int
+	for i, w := range words {
+		for _, c := range clauses {
+			switch c.kind {
+			case clauseTerm:
+				if w.word == c.words[0] {
+					spans = append(spans, [2]int{w.start, w.end})
+				}
+			case clausePrefix:
+				if strings.HasPrefix(w.word, c.words[0]) {
+					spans = append(spans, [2]int{w.start, w.end})
+				}
+			case clausePhrase:
+				if i+len(c.words) > len(words) {
+					continue
+				}
+				matched := true
+				for j, pw := range c.words {
+					if words[i+j].word != pw {
+						matched = false
+						break
+					}
+				}
+				if matched {
+					spans = append(spans, [2]int{w.start, words[i+len(c.words)-1].end})
+				}
+			}
+		}
+	}
+	return mergeSpans(spans)
+}
+
+// searchRegex scans every indexed line with re. Must hold mu.
+func (ix *Index) searchRegex(re *regexp.Regexp) []Hit {
+	var hits []Hit
+	for id, doc := range ix.docs {
+		hit := Hit{ID: id}
+		for n, line := range doc.lines {
+			locs := re.FindAllStringIndex(line, -1)
+			var spans [][2]int
+			for _, loc := range locs {
+				if loc[1] > loc[0] {
+					spans = append(spans, [2]int{loc[0], loc[1]})
+				}
+			}
+			if len(spans) > 0 {
+				hit.addMatch(n, line, spans)
+			}
+		}
+		if hit.MatchCount > 0 {
+			hits = append(hits, hit)
+		}
+	}
+	sortHits(hits)
+	return hits
+}
+
+// addMatch records a matching line, keeping at most maxMatchesPerFile snippets.
+func (h *Hit) addMatch(lineNo int, line string, spans [][2]int) {
+	h.MatchCount++
+	if len(h.Matches) >= maxMatchesPerFile {
+		return
+	}
+	snippet, highlights := makeSnippet(line, spans)
+	h.Matches = append(h.Matches, models.SearchMatch{
+		Line:       lineNo + 1,
+		Snippet:    snippet,
+		Highlights: highlights,
+	})
+}
+
+// makeSnippet trims long lines to a window around the first match and converts
+// byte spans into character offsets relative to the snippet.
+func makeSnippet(line string, spans [][2]int) (string, []models.SearchSpan) {
+	start, end := 0, len(line)
+	if len(line) > snippetWidth {
+		start = spans[0][0] - snippetWidth/3
+		if start < 0 {
+			start = 0
+		}
+		end = start + snippetWidth
+		if end > len(line) {
+			end = len(line)
+		}
+		for start > 0 && !utf8.RuneStart(line[start]) {
+			start--
+		}
+		for end < len(line) && !utf8.RuneStart(line[end]) {
+			end++
+		}
+	}
+
+	snippet := line[start:end]
+	var out []models.SearchSpan
+	for _, sp := range spans {
+		s, e := sp[0], sp[1]
+		if e <= start || s >= end {
+			continue
+		}
+		if s < start {
+			s = start
+		}
+		if e > end {
+			e = end
+		}
+		out = append(out, models.SearchSpan{
+			Start: utf8.RuneCountInString(line[start:s]),
+			End:   utf8.RuneCountInString(line[start:e]),
+		})
+	}
+	return strings.TrimRight(snippet, "\r"), out
+}
+
+// splitWords finds the words of a line with their byte offsets.
+func splitWords(line string) []wordSpan {
+	var words []wordSpan
+	start := -1
+	for i, r := range line {
+		if isWordRune(r) {
+			if start == -1 {
+				start = i
+			}
+			continue
+		}
+		if start != -1 {
+			words = append(words, wordSpan{start, i, strings.ToLower(line[start:i])})
+			start = -1
+		}
+	}
+	if start != -1 {
+		words = append(words, wordSpan{start, len(line), strings.ToLower(line[start:])})
+	}
+	return words
+}
+
+// mergeSpans sorts spans and merges overlapping ones.
+func mergeSpans(spans [][2]int) [][2]int {
+	if len(spans) < 2 {
+		return spans
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+	merged := spans[:1]
+	for _, sp := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if sp[0] <= last[1] {
+			if sp[1] > last[1] {
+				last[1] = sp[1]
+			}
+			continue
+		}
+		merged = append(merged, sp)
+	}
+	return merged
+}
+
+func sortHits(hits []Hit) {
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].MatchCount != hits[j].MatchCount {
+			return hits[i].MatchCount > hits[j].MatchCount
+		}
+		return hits[i].ID < hits[j].ID
+	})
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/cleanup"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/scanner"
+)
+
+// handleCleanupOrphans moves orphaned project directories to the trash.
+// POST /api/cleanup/orphans {"dirs": ["-home-me-old-repo"]}
+//
+// Each directory is checked again before it is trashed, so a project that
+// reappeared since the cleanup analysis is left alone.
+func (s *Server) handleCleanupOrphans(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.OrphanCleanupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	base := filepath.Join(scanner.GlobalClaudeDir(), "projects")
+	deleted := 0
+	var freed int64
+	var errors []string
+	var removedIDs, paths []string
+
+	for _, dir := range req.Dirs {
+		if !scanner.IsOrphanedProject(dir) {
+			errors = append(errors, dir+": not an orphaned project directory")
+			continue
+		}
+		path := filepath.Join(base, dir)
+		item, err := s.trash.Trash(path, nil, string(models.ReasonOrphanedProject))
+		if err != nil {
+			errors = append(errors, dir+": "+err.Error())
+			continue
+		}
+		deleted++
+		freed += item.Size
+		paths = append(paths, path)
+
+		prefix := path + string(os.PathSeparator)
+		for _, f := range s.store.Snapshot().Files {
+			if strings.HasPrefix(f.Path, prefix) {
+				removedIDs = append(removedIDs, f.ID)
+			}
+		}
+	}
+
+	s.touch(paths...)
+	s.store.Remove(removedIDs...)
+	s.index.Remove(removedIDs...)
+
+	writeJSON(w, map[string]interface{}{
+		"deleted": deleted,
+		"freed":   freed,
+		"errors":  errors,
+	})
+}
+
+// handleCleanupRules reads or replaces the cleanup rules.
+// GET /api/cleanup/rules
+// PUT /api/cleanup/rules {"rules": [...]}  — validated, then written to the rules file
+func (s *Server) handleCleanupRules(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		set, err := cleanup.Load(s.cleanupRules)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, set)
+
+	case http.MethodPut:
+		var req models.CleanupRuleSet
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := cleanup.Save(s.cleanupRules, req.Rules); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, models.CleanupRuleSet{Path: s.cleanupRules, Rules: req.Rules})
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
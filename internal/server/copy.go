@@ -0,0 +1,154 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/fsutil"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/scanner"
+)
+
+// copyFile places a copy of a file (or a whole skill directory) at the
+// equivalent location in each target project. Existing targets are reported
+// as conflicts unless overwrite is set; overwritten files keep their prior
+// content in history, overwritten skill directories go to the trash.
+// POST /api/files/{id}/copy  {projects: ["app", "/abs/path"], overwrite: false}
+func (s *Server) copyFile(w http.ResponseWriter, r *http.Request, entry models.FileEntry) {
+	var req models.CopyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(req.Projects) == 0 {
+		http.Error(w, "no target projects", http.StatusBadRequest)
+		return
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	var result models.CopyResult
+	for _, ref := range req.Projects {
+		target := s.copyTo(entry, ref, req.Overwrite)
+		switch target.Status {
+		case models.CopyCopied, models.CopyOverwritten:
+			result.Copied++
+		case models.CopyConflict:
+			result.Conflicts++
+		case models.CopyFailed:
+			result.Errors++
+		}
+		result.Targets = append(result.Targets, target)
+	}
+	writeJSON(w, result)
+}
+
+// copyTo copies entry into one project. Must hold writeMu.
+func (s *Server) copyTo(entry models.FileEntry, ref string, overwrite bool) models.CopyTarget {
+	target := models.CopyTarget{Project: ref}
+	fail := func(err error) models.CopyTarget {
+		target.Status = models.CopyFailed
+		target.Error = err.Error()
+		return target
+	}
+
+	root, _, err := s.resolveProject(ref)
+	if err != nil {
+		return fail(err)
+	}
+	plan, err := scanner.Relocate(entry.Path, models.ScopeProject, root, "")
+	if err != nil {
+		return fail(err)
+	}
+	target.Path = plan.Dst
+	if plan.Src == plan.Dst {
+		target.Status = models.CopySkipped
+		target.Error = "source and target are the same"
+		return target
+	}
+
+	target.Status = models.CopyCopied
+	if _, err := os.Lstat(plan.Dst); err == nil {
+		if !overwrite {
+			target.Status = models.CopyConflict
+			target.Error = "target already exists"
+			return target
+		}
+		target.Status = models.CopyOverwritten
+	}
+
+	if plan.IsDir {
+		err = s.copyDir(plan, target.Status == models.CopyOverwritten)
+	} else {
+		err = s.copySingle(plan)
+	}
+	if err != nil {
+		return fail(err)
+	}
+
+	var added []models.FileEntry
+	if plan.IsDir {
+		added, err = s.entriesUnder(plan.Dst)
+	} else {
+		var e models.FileEntry
+		if e, err = s.entryFor(plan.Dst); err == nil {
+			added = []models.FileEntry{e}
+		}
+	}
+	if err != nil {
+		log.Printf("Cannot read copied files at %s: %v", plan.Dst, err)
+	}
+	s.touch(plan.Dst)
+	s.store.Upsert(added...)
+	s.reindex(added...)
+	target.Files = added
+	return target
+}
+
+// copySingle copies one file, recording any content it replaces in history.
+// A new copy gets the source's permission bits; a replaced file keeps its own.
+func (s *Server) copySingle(plan scanner.Relocation) error {
+	info, err := os.Stat(plan.Src)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(plan.Src)
+	if err != nil {
+		return err
+	}
+	if old, err := os.ReadFile(plan.Dst); err == nil {
+		if _, err := s.history.Record(plan.Dst, old, "disk"); err != nil {
+			log.Printf("History record failed for %s: %v", plan.Dst, err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(plan.Dst), 0755); err != nil {
+		return err
+	}
+	if err := fsutil.WriteFileAtomic(plan.Dst, data, info.Mode().Perm()); err != nil {
+		return err
+	}
+	_, err = s.history.Record(plan.Dst, data, "copy")
+	return err
+}
+
+// copyDir copies a skill directory, moving an existing one to the trash first.
+func (s *Server) copyDir(plan scanner.Relocation, replace bool) error {
+	if replace {
+		replaced := s.entriesAt(plan.Dst, true)
+		if _, err := s.trash.Trash(plan.Dst, nil, "overwritten by copy"); err != nil {
+			return err
+		}
+		var ids []string
+		for _, e := range replaced {
+			ids = append(ids, e.ID)
+		}
+		s.touch(plan.Dst)
+		s.store.Remove(ids...)
+		s.index.Remove(ids...)
+	}
+	return fsutil.Copy(plan.Src, plan.Dst)
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/history"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/scanner"
+)
+
+func TestCopySingleMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing os.FileMode // mode of the file at the target, 0 for none
+		want     os.FileMode
+	}{
+		{"new", 0, 0755},
+		{"overwrite", 0600, 0600},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			src := filepath.Join(dir, "hook.sh")
+			dst := filepath.Join(dir, "other", "hook.sh")
+			if err := os.WriteFile(src, []byte("#!/bin/sh\n"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			os.Chmod(src, 0755)
+			if tt.existing != 0 {
+				os.MkdirAll(filepath.Dir(dst), 0755)
+				os.WriteFile(dst, []byte("old\n"), tt.existing)
+				os.Chmod(dst, tt.existing)
+			}
+
+			s := &Server{history: history.New(t.TempDir())}
+			if err := s.copySingle(scanner.Relocation{Src: src, Dst: dst}); err != nil {
+				t.Fatal(err)
+			}
+			info, err := os.Stat(dst)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := info.Mode().Perm(); got != tt.want {
+				t.Errorf("copy has mode %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
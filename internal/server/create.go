@@ -0,0 +1,121 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/fsutil"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/scanner"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/templates"
+)
+
+// handleProjects lists the projects discovered in the current scan.
+// GET /api/projects
+func (s *Server) handleProjects(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, scanner.Projects(s.store.Snapshot().Files))
+}
+
+// resolveProject turns a project reference into a project directory. The
+// reference is either an absolute path to an existing directory or the name
+// of a discovered project, which must be unambiguous.
+func (s *Server) resolveProject(ref string) (string, int, error) {
+	if ref == "" {
+		return "", http.StatusBadRequest, fmt.Errorf("missing project")
+	}
+	if filepath.IsAbs(ref) {
+		info, err := os.Stat(ref)
+		if err != nil || !info.IsDir() {
+			return "", http.StatusBadRequest, fmt.Errorf("project directory %q does not exist", ref)
+		}
+		return filepath.Clean(ref), 0, nil
+	}
+
+	var matches []string
+	for _, p := range scanner.Projects(s.store.Snapshot().Files) {
+		if p.Name == ref {
+			matches = append(matches, p.Path)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", http.StatusNotFound, fmt.Errorf("unknown project %q", ref)
+	case 1:
+		return matches[0], 0, nil
+	default:
+		return "", http.StatusConflict, fmt.Errorf("project name %q is ambiguous, use one of: %s", ref, strings.Join(matches, ", "))
+	}
+}
+
+// createFile creates a new agent, skill, command or CLAUDE.md from a template.
+// POST /api/files  {category, scope, project, name, content?}
+func (s *Server) createFile(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Scope == "" {
+		req.Scope = models.ScopeGlobal
+	}
+	if req.Scope != models.ScopeGlobal && req.Scope != models.ScopeProject {
+		http.Error(w, "invalid scope", http.StatusBadRequest)
+		return
+	}
+
+	var projectRoot string
+	if req.Scope == models.ScopeProject {
+		root, status, err := s.resolveProject(req.Project)
+		if err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		projectRoot = root
+	}
+
+	path, err := scanner.TargetPath(req.Category, req.Scope, projectRoot, req.Name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	content := templates.For(req.Category, strings.TrimSuffix(req.Name, ".md"))
+	if req.Content != nil {
+		content = *req.Content
+	}
+
+	if err := fsutil.WriteFileExclusive(path, []byte(content), 0644); err != nil {
+		if os.IsExist(err) {
+			http.Error(w, "file already exists: "+path, http.StatusConflict)
+			return
+		}
+		http.Error(w, "cannot create file: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	entry, err := s.entryFor(path)
+	if err != nil {
+		http.Error(w, "cannot read created file: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.touch(path)
+	s.store.Upsert(entry)
+	s.index.Update(entry.ID, content)
+
+	etag := contentETag([]byte(content))
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Location", "/api/files/"+entry.ID)
+	writeJSONStatus(w, http.StatusCreated, models.FileContent{
+		FileEntry: entry,
+		Content:   content,
+		ETag:      etag,
+	})
+}
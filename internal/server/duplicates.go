@@ -0,0 +1,72 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/diff"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/dupes"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+)
+
+// handleDuplicates groups files with identical content and near duplicates.
+// GET /api/duplicates?threshold=80
+//
+// threshold is the minimum similarity in percent for near duplicates.
+func (s *Server) handleDuplicates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	threshold := float64(dupes.DefaultThreshold)
+	if v := r.URL.Query().Get("threshold"); v != "" {
+		t, err := strconv.ParseFloat(v, 64)
+		if err != nil || t <= 0 || t > 100 {
+			http.Error(w, "invalid threshold, expected a percentage above 0", http.StatusBadRequest)
+			return
+		}
+		threshold = t
+	}
+
+	writeJSON(w, dupes.Analyze(s.store.Snapshot().Files, threshold))
+}
+
+// handleDuplicateDiff compares two files.
+// GET /api/duplicates/diff?a={id}&b={id}
+func (s *Server) handleDuplicateDiff(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var entries [2]models.FileEntry
+	var contents [2]string
+	for i, param := range []string{"a", "b"} {
+		id := r.URL.Query().Get(param)
+		entry, ok := s.store.Find(id)
+		if !ok {
+			http.Error(w, "file not found: "+param, http.StatusNotFound)
+			return
+		}
+		data, err := os.ReadFile(entry.Path)
+		if err != nil {
+			http.Error(w, "cannot read file: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		entries[i], contents[i] = entry, string(data)
+	}
+
+	hunks := diff.Hunks(contents[0], contents[1], 3)
+	if hunks == nil {
+		hunks = []diff.Hunk{}
+	}
+	writeJSON(w, models.DuplicateDiff{
+		A:          entries[0],
+		B:          entries[1],
+		Similarity: dupes.Similarity(contents[0], contents[1]),
+		Hunks:      hunks,
+		Unified:    diff.Unified(entries[0].RelPath, entries[1].RelPath, contents[0], contents[1]),
+	})
+}
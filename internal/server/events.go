@@ -0,0 +1,213 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/watcher"
+)
+
+// eventBuffer is how many events a slow client may fall behind before it is
+// told to reload instead.
+const eventBuffer = 64
+
+// heartbeatInterval keeps idle event streams from being closed by proxies.
+const heartbeatInterval = 30 * time.Second
+
+// hub fans file events out to the connected /api/events clients.
+type hub struct {
+	mu      sync.Mutex
+	clients map[chan models.FileEvent]bool
+}
+
+func newHub() *hub {
+	return &hub{clients: make(map[chan models.FileEvent]bool)}
+}
+
+func (h *hub) subscribe() chan models.FileEvent {
+	ch := make(chan models.FileEvent, eventBuffer)
+	h.mu.Lock()
+	h.clients[ch] = true
+	h.mu.Unlock()
+	return ch
+}
+
+func (h *hub) unsubscribe(ch chan models.FileEvent) {
+	h.mu.Lock()
+	delete(h.clients, ch)
+	h.mu.Unlock()
+}
+
+// publish sends events to every client without blocking. A client whose
+// buffer is full has its pending events replaced by a single rescan event.
+func (h *hub) publish(events ...models.FileEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.clients {
+		for _, ev := range events {
+			select {
+			case ch <- ev:
+				continue
+			default:
+			}
+			drain(ch)
+			select {
+			case ch <- models.FileEvent{Type: models.EventRescan}:
+			default: // unreachable: only publish sends, and it holds mu
+			}
+			break
+		}
+	}
+}
+
+// drain empties ch without blocking; its reader may be receiving too.
+func drain(ch chan models.FileEvent) {
+	for {
+		select {
+		case <-ch:
+		default:
+			return
+		}
+	}
+}
+
+// handleEvents streams file changes as server-sent events.
+// GET /api/events
+//
+// Each event is named after its type (add, modify, delete, rescan) and
+// carries a models.FileEvent as JSON data.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	ch := s.events.subscribe()
+	defer s.events.unsubscribe(ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case ev := <-ch:
+			data, err := json.Marshal(ev)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, data)
+		}
+		flusher.Flush()
+	}
+}
+
+// watchFiles keeps the store current with changes on disk until ctx is done.
+func (s *Server) watchFiles(ctx context.Context) {
+	err := watcher.Run(ctx, s.scanner, func(b watcher.Batch) {
+		if b.Resync {
+			if err := s.refresh(ctx); err != nil {
+				log.Printf("Rescan after lost file events failed: %v", err)
+				return
+			}
+			s.events.publish(models.FileEvent{Type: models.EventRescan})
+			return
+		}
+		s.events.publish(s.applyChanges(b.Paths)...)
+	})
+	if err != nil {
+		log.Printf("File watching stopped: %v", err)
+	}
+}
+
+// applyChanges brings the store and search index in line with the current
+// state of the given paths and returns the resulting events. A path that no
+// longer exists removes every entry at or below it; a directory adds the
+// files a scan would find in it.
+func (s *Server) applyChanges(paths []string) []models.FileEvent {
+	s.scanMu.Lock()
+	defer s.scanMu.Unlock()
+	return s.apply(paths)
+}
+
+// apply is applyChanges for a caller holding scanMu.
+func (s *Server) apply(paths []string) []models.FileEvent {
+	current := s.store.Snapshot().Files
+	byPath := make(map[string]models.FileEntry, len(current))
+	for _, f := range current {
+		byPath[f.Path] = f
+	}
+
+	var events []models.FileEvent
+	var changed []models.FileEntry
+	var removed []string
+	seen := make(map[string]bool)
+	update := func(e models.FileEntry) {
+		if seen[e.ID] {
+			return
+		}
+		seen[e.ID] = true
+		old, ok := byPath[e.Path]
+		switch {
+		case !ok:
+			events = append(events, models.FileEvent{Type: models.EventAdd, ID: e.ID, File: &e})
+		case old.Size != e.Size || !old.ModTime.Equal(e.ModTime) || old.ReadOnly != e.ReadOnly:
+			events = append(events, models.FileEvent{Type: models.EventModify, ID: e.ID, File: &e})
+		default:
+			return
+		}
+		changed = append(changed, e)
+	}
+
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		switch {
+		case err != nil:
+			prefix := p + string(os.PathSeparator)
+			for _, f := range current {
+				if (f.Path == p || strings.HasPrefix(f.Path, prefix)) && !seen[f.ID] {
+					seen[f.ID] = true
+					removed = append(removed, f.ID)
+					events = append(events, models.FileEvent{Type: models.EventDelete, ID: f.ID})
+				}
+			}
+		case info.IsDir():
+			entries, _ := s.scanner.MatchesUnder(p)
+			for _, e := range entries {
+				update(e)
+			}
+		case s.scanner.Matches(p):
+			if e, err := s.entryFor(p); err == nil {
+				update(e)
+			}
+		}
+	}
+
+	s.store.Upsert(changed...)
+	s.reindex(changed...)
+	if len(removed) > 0 {
+		s.store.Remove(removed...)
+		s.index.Remove(removed...)
+	}
+	return events
+}
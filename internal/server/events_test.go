@@ -0,0 +1,57 @@
+package server
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+)
+
+// TestHubOverflow publishes more events than a client buffers while the
+// client reads concurrently; publish must never block.
+func TestHubOverflow(t *testing.T) {
+	h := newHub()
+	ch := h.subscribe()
+
+	stop := make(chan struct{})
+	var reader sync.WaitGroup
+	reader.Add(1)
+	go func() {
+		defer reader.Done()
+		for {
+			select {
+			case <-ch:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 100*eventBuffer; i++ {
+			h.publish(models.FileEvent{Type: models.EventModify, ID: "x"})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("publish blocked")
+	}
+	close(stop)
+	reader.Wait()
+
+	// Without a reader, overflow leaves a single rescan event.
+	drain(ch)
+	for i := 0; i < eventBuffer+1; i++ {
+		h.publish(models.FileEvent{Type: models.EventModify, ID: "x"})
+	}
+	if len(ch) != 1 {
+		t.Fatalf("%d events buffered after overflow, want 1", len(ch))
+	}
+	if ev := <-ch; ev.Type != models.EventRescan {
+		t.Errorf("event after overflow is %q, want %q", ev.Type, models.EventRescan)
+	}
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/diff"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/history"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+)
+
+// currentRevision names the on-disk content in diff requests.
+const currentRevision = "current"
+
+// handleHistory serves the version history of one file.
+// GET  /api/files/{id}/history                      — list revisions, newest first
+// GET  /api/files/{id}/history/diff?from=a&to=b     — diff two revisions ("current" = on disk)
+// GET  /api/files/{id}/history/{rev}                — fetch one revision
+// POST /api/files/{id}/history/{rev}/restore        — restore via the save path (If-Match required, body {force} optional)
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request, entry models.FileEntry, sub string) {
+	rev, action, _ := strings.Cut(sub, "/")
+
+	switch {
+	case rev == "" && r.Method == http.MethodGet:
+		revs, err := s.history.List(entry.Path)
+		if err != nil {
+			http.Error(w, "cannot read history: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if revs == nil {
+			revs = []models.Revision{}
+		}
+		writeJSON(w, revs)
+
+	case rev == "diff" && action == "" && r.Method == http.MethodGet:
+		s.diffRevisions(w, r, entry)
+
+	case rev != "" && action == "" && r.Method == http.MethodGet:
+		content, err := s.history.Get(entry.Path, rev)
+		if err != nil {
+			writeHistoryError(w, err)
+			return
+		}
+		rc := models.RevisionContent{
+			Revision: models.Revision{ID: rev, Size: int64(len(content))},
+			Content:  string(content),
+		}
+		// Report when and how this revision was recorded.
+		if revs, err := s.history.List(entry.Path); err == nil {
+			for _, r := range revs {
+				if r.ID == rev {
+					rc.Revision = r
+					break
+				}
+			}
+		}
+		writeJSON(w, rc)
+
+	case rev != "" && action == "restore" && r.Method == http.MethodPost:
+		if entry.ReadOnly {
+			http.Error(w, "file is read-only", http.StatusForbidden)
+			return
+		}
+		ifMatch := r.Header.Get("If-Match")
+		if ifMatch == "" {
+			http.Error(w, "If-Match header required", http.StatusPreconditionRequired)
+			return
+		}
+		// The body is optional; only force is read from it.
+		var req models.SaveRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		content, err := s.history.Get(entry.Path, rev)
+		if err != nil {
+			writeHistoryError(w, err)
+			return
+		}
+		req = models.SaveRequest{Content: string(content), Force: req.Force}
+		if !validContent(w, entry, req) {
+			return
+		}
+		s.commitSave(w, entry, ifMatch, req, "restore")
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) diffRevisions(w http.ResponseWriter, r *http.Request, entry models.FileEntry) {
+	from, to := r.URL.Query().Get("from"), r.URL.Query().Get("to")
+	if from == "" {
+		http.Error(w, "missing from revision", http.StatusBadRequest)
+		return
+	}
+	if to == "" {
+		to = currentRevision
+	}
+
+	a, err := s.revisionContent(entry, from)
+	if err != nil {
+		writeHistoryError(w, err)
+		return
+	}
+	b, err := s.revisionContent(entry, to)
+	if err != nil {
+		writeHistoryError(w, err)
+		return
+	}
+
+	hunks := diff.Hunks(string(a), string(b), 3)
+	if hunks == nil {
+		hunks = []diff.Hunk{}
+	}
+	writeJSON(w, models.RevisionDiff{
+		From:    from,
+		To:      to,
+		Hunks:   hunks,
+		Unified: diff.Unified(from, to, string(a), string(b)),
+	})
+}
+
+// revisionContent returns a stored revision, or the file on disk for "current".
+func (s *Server) revisionContent(entry models.FileEntry, rev string) ([]byte, error) {
+	if rev == currentRevision {
+		return os.ReadFile(entry.Path)
+	}
+	return s.history.Get(entry.Path, rev)
+}
+
+func writeHistoryError(w http.ResponseWriter, err error) {
+	if errors.Is(err, history.ErrNotFound) || os.IsNotExist(err) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
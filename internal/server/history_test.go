@@ -0,0 +1,62 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/history"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/scanner"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/search"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/store"
+)
+
+func TestRestoreValidates(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	sc, err := scanner.New(nil, scanner.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{scanner: sc, store: store.New(), index: search.New(), history: history.New(t.TempDir())}
+
+	path := filepath.Join(home, "settings.json")
+	rev, err := s.history.Record(path, []byte("{broken"), "save")
+	if err != nil {
+		t.Fatal(err)
+	}
+	current := []byte("{}\n")
+	if err := os.WriteFile(path, current, 0644); err != nil {
+		t.Fatal(err)
+	}
+	entry := models.FileEntry{ID: "id", Path: path}
+
+	tests := []struct {
+		name, body string
+		want       int
+		content    string
+	}{
+		{"no body", "", http.StatusUnprocessableEntity, "{}\n"},
+		{"not forced", `{"force": false}`, http.StatusUnprocessableEntity, "{}\n"},
+		{"forced", `{"force": true}`, http.StatusOK, "{broken"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			r.Header.Set("If-Match", contentETag(current))
+			w := httptest.NewRecorder()
+			s.handleHistory(w, r, entry, rev.ID+"/restore")
+			if w.Code != tt.want {
+				t.Fatalf("status %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if data, _ := os.ReadFile(path); string(data) != tt.content {
+				t.Errorf("file holds %q, want %q", data, tt.content)
+			}
+		})
+	}
+}
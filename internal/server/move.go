@@ -0,0 +1,126 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/fsutil"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/scanner"
+)
+
+// moveFile renames a file or moves it between global and project scope.
+// Skills move as a whole directory. Existing targets are never overwritten.
+// POST /api/files/{id}/move  {name?, scope?, project?}
+func (s *Server) moveFile(w http.ResponseWriter, r *http.Request, entry models.FileEntry) {
+	if entry.ReadOnly {
+		http.Error(w, "file is read-only", http.StatusForbidden)
+		return
+	}
+
+	var req models.MoveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var plan scanner.Relocation
+	var err error
+	if req.Scope == "" && req.Project == "" {
+		plan, err = scanner.Rename(entry.Path, req.Name)
+	} else {
+		scope := req.Scope
+		if scope == "" {
+			scope = models.ScopeProject
+		}
+		var root string
+		if scope == models.ScopeProject {
+			var status int
+			if root, status, err = s.resolveProject(req.Project); err != nil {
+				http.Error(w, err.Error(), status)
+				return
+			}
+		} else if scope != models.ScopeGlobal {
+			http.Error(w, "invalid scope", http.StatusBadRequest)
+			return
+		}
+		plan, err = scanner.Relocate(entry.Path, scope, root, req.Name)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if plan.Src == plan.Dst {
+		http.Error(w, "source and destination are the same", http.StatusBadRequest)
+		return
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if err := fsutil.Move(plan.Src, plan.Dst); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			http.Error(w, "target already exists: "+plan.Dst, http.StatusConflict)
+			return
+		}
+		http.Error(w, "cannot move file: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	moved := s.entriesAt(plan.Src, plan.IsDir)
+	var added []models.FileEntry
+	if plan.IsDir {
+		added, err = s.entriesUnder(plan.Dst)
+	} else {
+		var e models.FileEntry
+		if e, err = s.entryFor(plan.Dst); err == nil {
+			added = []models.FileEntry{e}
+		}
+	}
+	if err != nil {
+		log.Printf("Cannot read moved files at %s: %v", plan.Dst, err)
+	}
+
+	// History is keyed by path, so carry it over to the new location.
+	var ids []string
+	for _, old := range moved {
+		ids = append(ids, old.ID)
+		newPath := plan.Dst + strings.TrimPrefix(old.Path, plan.Src)
+		if err := s.history.Move(old.Path, newPath); err != nil {
+			log.Printf("History move failed for %s: %v", old.Path, err)
+		}
+	}
+	s.touch(plan.Src, plan.Dst)
+	s.store.Remove(ids...)
+	s.index.Remove(ids...)
+	s.store.Upsert(added...)
+	s.reindex(added...)
+
+	if added == nil {
+		added = []models.FileEntry{}
+	}
+	writeJSON(w, map[string]interface{}{
+		"success": true,
+		"from":    plan.Src,
+		"to":      plan.Dst,
+		"files":   added,
+	})
+}
+
+// entriesAt returns the scanned entries for path, or for every file below it
+// when isDir is set.
+func (s *Server) entriesAt(path string, isDir bool) []models.FileEntry {
+	var out []models.FileEntry
+	prefix := path + string(filepath.Separator)
+	for _, f := range s.store.Snapshot().Files {
+		if f.Path == path || (isDir && strings.HasPrefix(f.Path, prefix)) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
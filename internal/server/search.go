@@ -0,0 +1,68 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+)
+
+// defaultSearchLimit caps the number of files returned by a content search.
+const defaultSearchLimit = 100
+
+// handleSearch runs a full-text query over file contents.
+// GET /api/search?q=pnpm&mode=text|regex&category=memory&limit=50
+//
+// In text mode, every term must appear in a file; "quoted phrases" match
+// consecutive words and a trailing * (e.g. inst*) matches by prefix.
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	query := q.Get("q")
+	mode := q.Get("mode")
+	category := q.Get("category")
+	limit := defaultSearchLimit
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	hits, err := s.index.Search(query, mode)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result := models.SearchResult{Query: query, Mode: mode, Hits: []models.SearchHit{}}
+	if result.Mode == "" {
+		result.Mode = "text"
+	}
+	for _, h := range hits {
+		// Files removed since the index was built are silently dropped.
+		entry, ok := s.store.Find(h.ID)
+		if !ok {
+			continue
+		}
+		if category != "" && string(entry.Category) != category {
+			continue
+		}
+		result.TotalHits++
+		if len(result.Hits) < limit {
+			result.Hits = append(result.Hits, models.SearchHit{
+				FileEntry:  entry,
+				Matches:    h.Matches,
+				MatchCount: h.MatchCount,
+			})
+		}
+	}
+
+	writeJSON(w, result)
+}
@@ -1,42 +1,119 @@
 package server
 
 import (
+	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"io/fs"
 	"log"
 	"net/http"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/MojtabaTajik/ClaudeShelf/internal/cleanup"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/diff"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/dupes"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/fsutil"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/history"
 	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
 	"github.com/MojtabaTajik/ClaudeShelf/internal/scanner"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/schema"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/search"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/sessions"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/store"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/trash"
 )
 
 // Server holds the HTTP server state.
 type Server struct {
-	port    int
-	scanner *scanner.Scanner
-	result  *models.ScanResult
+	port     int
+	scanner  *scanner.Scanner
+	store    *store.Store
+	index    *search.Index
+	trash    *trash.Bin
+	history  *history.Store
+	sessions *sessions.Index
+	events   *hub
 	staticFS fs.FS
+
+	// cleanupRules is the path of the cleanup rules file.
+	cleanupRules string
+	// watch enables pushing filesystem changes to /api/events clients.
+	watch bool
+
+	// scanMu serialises rescans so an older scan cannot overwrite a newer one.
+	scanMu sync.Mutex
+	// changedMu guards changed and the store replacement at the end of a rescan.
+	changedMu sync.Mutex
+	// changed collects the paths the API modifies while a rescan runs, so they
+	// can be re-applied over the scan's possibly stale result; nil otherwise.
+	changed map[string]bool
+	// progress is the state of the running or most recent full scan.
+	progress atomic.Pointer[models.ScanProgress]
+	// writeMu makes the If-Match check and the write that follows atomic
+	// with respect to other saves through this server.
+	writeMu sync.Mutex
+}
+
+// Options configures the server's persistent subsystems.
+type Options struct {
+	// Trash receives deleted files so they can be restored later.
+	Trash *trash.Bin
+	// History keeps prior versions of files overwritten through the API.
+	History *history.Store
+	// CleanupRules is the path of the JSON file holding the cleanup rules;
+	// the built-in rules apply while it does not exist.
+	CleanupRules string
+	// Watch keeps the file list current by watching the scanned directories.
+	Watch bool
 }
 
 // New creates a new server instance.
-func New(port int, sc *scanner.Scanner, staticFS fs.FS) *Server {
+func New(port int, sc *scanner.Scanner, staticFS fs.FS, opts Options) *Server {
 	return &Server{
 		port:     port,
 		scanner:  sc,
+		store:    store.New(),
+		index:    search.New(),
+		trash:    opts.Trash,
+		history:  opts.History,
+		sessions: sessions.New(filepath.Join(scanner.GlobalClaudeDir(), "projects")),
+		events:   newHub(),
 		staticFS: staticFS,
+
+		cleanupRules: opts.CleanupRules,
+		watch:        opts.Watch,
 	}
 }
 
 // Start runs the HTTP server.
 func (s *Server) Start() error {
-	if err := s.refresh(); err != nil {
+	if err := s.refresh(context.Background()); err != nil {
 		return fmt.Errorf("initial scan failed: %w", err)
 	}
+	if n, err := s.trash.PurgeExpired(); err != nil {
+		log.Printf("Trash purge failed: %v", err)
+	} else if n > 0 {
+		log.Printf("Purged %d expired trash item(s)", n)
+	}
 
+	if s.watch {
+		go s.watchFiles(context.Background())
+	}
+
+	addr := fmt.Sprintf(":%d", s.port)
+	log.Printf("ClaudeShelf running at http://localhost%s", addr)
+	return http.ListenAndServe(addr, s.handler())
+}
+
+// handler routes requests to the API and the embedded static files.
+func (s *Server) handler() http.Handler {
 	mux := http.NewServeMux()
 
 	// API routes
@@ -45,28 +122,148 @@ func (s *Server) Start() error {
 	mux.HandleFunc("/api/files/", s.handleFileByID) // /api/files/{id}
 	mux.HandleFunc("/api/rescan", s.handleRescan)
 	mux.HandleFunc("/api/cleanup", s.handleCleanup)
+	mux.HandleFunc("/api/cleanup/orphans", s.handleCleanupOrphans)
+	mux.HandleFunc("/api/cleanup/rules", s.handleCleanupRules)
+	mux.HandleFunc("/api/duplicates", s.handleDuplicates)
+	mux.HandleFunc("/api/duplicates/diff", s.handleDuplicateDiff)
 	mux.HandleFunc("/api/categories", s.handleCategories)
+	mux.HandleFunc("/api/search", s.handleSearch)
+	mux.HandleFunc("/api/projects", s.handleProjects)
+	mux.HandleFunc("/api/projects/", s.handleProjectByName) // /api/projects/{name}/...
+	mux.HandleFunc("/api/trash", s.handleTrash)
+	mux.HandleFunc("/api/trash/purge", s.handleTrashPurge)
+	mux.HandleFunc("/api/trash/", s.handleTrashItem) // /api/trash/{id}[/restore]
+	mux.HandleFunc("/api/sessions", s.handleSessions)
+	mux.HandleFunc("/api/sessions/", s.handleSessionByID) // /api/sessions/{id}[/turns]
+	mux.HandleFunc("/api/usage", s.handleUsage)
+	mux.HandleFunc("/api/events", s.handleEvents)
 
 	// Static files (embedded)
 	mux.Handle("/", http.FileServer(http.FS(s.staticFS)))
-
-	addr := fmt.Sprintf(":%d", s.port)
-	log.Printf("ClaudeShelf running at http://localhost%s", addr)
-	return http.ListenAndServe(addr, mux)
+	return mux
 }
 
-func (s *Server) refresh() error {
-	result, err := s.scanner.Scan()
+// refresh rescans everything, publishing progress to /api/events clients.
+func (s *Server) refresh(ctx context.Context) error {
+	s.scanMu.Lock()
+	defer s.scanMu.Unlock()
+
+	s.changedMu.Lock()
+	s.changed = make(map[string]bool)
+	s.changedMu.Unlock()
+
+	result, err := s.scanner.ScanContext(ctx, func(p models.ScanProgress) {
+		s.progress.Store(&p)
+		s.events.publish(models.FileEvent{Type: models.EventScan, Progress: &p})
+	})
+
+	s.changedMu.Lock()
+	changed := s.changed
+	s.changed = nil
+	var previous []models.FileEntry
+	if err == nil {
+		previous = s.store.Snapshot().Files
+		s.store.Replace(result)
+	}
+	s.changedMu.Unlock()
+
 	if err != nil {
+		s.progress.Store(&models.ScanProgress{Done: true})
 		return err
 	}
-	s.result = result
+	s.updateIndex(previous, result.Files)
+
+	// The scan may have listed these before the API changed them.
+	if len(changed) > 0 {
+		paths := make([]string, 0, len(changed))
+		for p := range changed {
+			paths = append(paths, p)
+		}
+		sort.Strings(paths)
+		s.apply(paths)
+	}
 	return nil
 }
 
+// updateIndex brings the search index from the files of the previous scan
+// to those of the current one, reading only files that are new or whose size
+// or modification time changed.
+func (s *Server) updateIndex(previous, current []models.FileEntry) {
+	if len(previous) == 0 {
+		s.index.Rebuild(current)
+		return
+	}
+	old := make(map[string]models.FileEntry, len(previous))
+	for _, f := range previous {
+		old[f.ID] = f
+	}
+	var changed []models.FileEntry
+	for _, f := range current {
+		o, ok := old[f.ID]
+		delete(old, f.ID)
+		if !ok || o.Size != f.Size || !o.ModTime.Equal(f.ModTime) {
+			changed = append(changed, f)
+		}
+	}
+	removed := make([]string, 0, len(old))
+	for id := range old {
+		removed = append(removed, id)
+	}
+	s.index.Remove(removed...)
+	s.reindex(changed...)
+}
+
+// touch records that the API is about to update the store for paths, which
+// have already changed on disk. It must be called before the store update:
+// a rescan that replaces the store after touch re-applies the paths, and one
+// that replaced it before leaves the update in place.
+func (s *Server) touch(paths ...string) {
+	s.changedMu.Lock()
+	defer s.changedMu.Unlock()
+	if s.changed == nil {
+		return
+	}
+	for _, p := range paths {
+		s.changed[p] = true
+	}
+}
+
+// entryFor builds the entry for a file changed outside a rescan, tagged
+// with its search path and workspace.
+func (s *Server) entryFor(path string) (models.FileEntry, error) {
+	e, err := scanner.EntryFor(path)
+	if err == nil {
+		s.scanner.Tag(&e)
+	}
+	return e, err
+}
+
+// entriesUnder is entryFor for every Claude file below dir.
+func (s *Server) entriesUnder(dir string) ([]models.FileEntry, error) {
+	entries, err := scanner.EntriesUnder(dir)
+	for i := range entries {
+		s.scanner.Tag(&entries[i])
+	}
+	return entries, err
+}
+
+// reindex refreshes the search index for entries changed outside a rescan.
+func (s *Server) reindex(entries ...models.FileEntry) {
+	for _, e := range entries {
+		if data, err := os.ReadFile(e.Path); err == nil {
+			s.index.Update(e.ID, string(data))
+		}
+	}
+}
+
 // handleFiles returns all discovered files, with optional query params for filtering.
-// GET /api/files?category=memory&search=keyword
+// GET  /api/files?category=memory&search=keyword&workspace=default
+// POST /api/files  — create a new file, see createFile
 func (s *Server) handleFiles(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		s.createFile(w, r)
+		return
+	}
 	if r.Method != http.MethodGet {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
@@ -74,14 +271,18 @@ func (s *Server) handleFiles(w http.ResponseWriter, r *http.Request) {
 
 	category := r.URL.Query().Get("category")
 	search := strings.ToLower(r.URL.Query().Get("search"))
+	workspace := r.URL.Query().Get("workspace")
 
-	files := s.result.Files
+	files := s.store.Snapshot().Files
 	var filtered []models.FileEntry
 
 	for _, f := range files {
 		if category != "" && string(f.Category) != category {
 			continue
 		}
+		if workspace != "" && f.Workspace != workspace {
+			continue
+		}
 		if search != "" {
 			if !strings.Contains(strings.ToLower(f.Name), search) &&
 				!strings.Contains(strings.ToLower(f.RelPath), search) {
@@ -94,35 +295,57 @@ func (s *Server) handleFiles(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, filtered)
 }
 
-// handleFileByID handles GET (read) and PUT (save) for a single file.
-// GET /api/files/{id}
-// PUT /api/files/{id}
+// handleFileByID handles GET (read), PUT (save) and DELETE for a single file.
+// GET /api/files/{id}     — responds with an ETag of the content
+// PUT /api/files/{id}     — requires If-Match with that ETag; JSON is validated (422) unless forced
+// DELETE /api/files/{id}?reason=...  — moves the file to the trash
+// POST /api/files/{id}/move          — see moveFile
+// POST /api/files/{id}/copy          — see copyFile
+// /api/files/{id}/history/...        — see handleHistory
 func (s *Server) handleFileByID(w http.ResponseWriter, r *http.Request) {
-	id := strings.TrimPrefix(r.URL.Path, "/api/files/")
+	rest := strings.TrimPrefix(r.URL.Path, "/api/files/")
+	id, sub, _ := strings.Cut(rest, "/")
 	if id == "" {
 		http.Error(w, "missing file id", http.StatusBadRequest)
 		return
 	}
 
-	entry := s.findFile(id)
-	if entry == nil {
+	entry, ok := s.store.Find(id)
+	if !ok {
 		http.Error(w, "file not found", http.StatusNotFound)
 		return
 	}
 
+	if sub == "history" || strings.HasPrefix(sub, "history/") {
+		s.handleHistory(w, r, entry, strings.TrimPrefix(strings.TrimPrefix(sub, "history"), "/"))
+		return
+	}
+	if sub == "move" && r.Method == http.MethodPost {
+		s.moveFile(w, r, entry)
+		return
+	}
+	if sub == "copy" && r.Method == http.MethodPost {
+		s.copyFile(w, r, entry)
+		return
+	}
+	if sub != "" {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
 	switch r.Method {
 	case http.MethodGet:
 		s.readFile(w, entry)
 	case http.MethodPut:
 		s.saveFile(w, r, entry)
 	case http.MethodDelete:
-		s.deleteFile(w, entry)
+		s.deleteFile(w, entry, r.URL.Query().Get("reason"))
 	default:
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 	}
 }
 
-func (s *Server) readFile(w http.ResponseWriter, entry *models.FileEntry) {
+func (s *Server) readFile(w http.ResponseWriter, entry models.FileEntry) {
 	data, err := os.ReadFile(entry.Path)
 	if err != nil {
 		http.Error(w, "cannot read file: "+err.Error(), http.StatusInternalServerError)
@@ -130,63 +353,148 @@ func (s *Server) readFile(w http.ResponseWriter, entry *models.FileEntry) {
 	}
 
 	fc := models.FileContent{
-		FileEntry: *entry,
+		FileEntry: entry,
 		Content:   string(data),
+		ETag:      contentETag(data),
 	}
+	w.Header().Set("ETag", fc.ETag)
 	writeJSON(w, fc)
 }
 
-func (s *Server) saveFile(w http.ResponseWriter, r *http.Request, entry *models.FileEntry) {
+func (s *Server) saveFile(w http.ResponseWriter, r *http.Request, entry models.FileEntry) {
 	if entry.ReadOnly {
 		http.Error(w, "file is read-only", http.StatusForbidden)
 		return
 	}
 
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		http.Error(w, "If-Match header required", http.StatusPreconditionRequired)
+		return
+	}
+
 	var req models.SaveRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	if err := os.WriteFile(entry.Path, []byte(req.Content), 0644); err != nil {
+	if !validContent(w, entry, req) {
+		return
+	}
+	s.commitSave(w, entry, ifMatch, req, "save")
+}
+
+// validContent reports whether req.Content passes the schema for the entry's
+// file, or req.Force skips the check. Otherwise it writes a 422 response.
+func validContent(w http.ResponseWriter, entry models.FileEntry, req models.SaveRequest) bool {
+	if req.Force {
+		return true
+	}
+	name, errs := schema.Validate(entry.Path, []byte(req.Content))
+	if len(errs) == 0 {
+		return true
+	}
+	writeJSONStatus(w, http.StatusUnprocessableEntity, models.ValidationFailure{
+		Error:  "content failed validation; set force to save anyway",
+		Schema: name,
+		Errors: errs,
+	})
+	return false
+}
+
+// commitSave writes req.Content to the entry's file if ifMatch still matches
+// the content on disk. Both the replaced and the new content are recorded in
+// the file's history under source. Every content write goes through here.
+func (s *Server) commitSave(w http.ResponseWriter, entry models.FileEntry, ifMatch string, req models.SaveRequest, source string) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	current, err := os.ReadFile(entry.Path)
+	if err != nil {
+		http.Error(w, "cannot read file: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if etag := contentETag(current); !etagMatches(ifMatch, etag) {
+		writeConflict(w, etag, string(current), req)
+		return
+	}
+
+	if _, err := s.history.Record(entry.Path, current, "disk"); err != nil {
+		log.Printf("History record failed for %s: %v", entry.Path, err)
+	}
+	if err := fsutil.WriteFileAtomic(entry.Path, []byte(req.Content), 0644); err != nil {
 		http.Error(w, "cannot write file: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
+	if _, err := s.history.Record(entry.Path, []byte(req.Content), source); err != nil {
+		log.Printf("History record failed for %s: %v", entry.Path, err)
+	}
 
-	// Re-read file info after save
-	info, err := os.Stat(entry.Path)
-	if err == nil {
-		entry.Size = info.Size()
-		entry.ModTime = info.ModTime()
+	s.touch(entry.Path)
+	s.index.Update(entry.ID, req.Content)
+
+	// Re-read the entry after save; size, mod time and frontmatter may have changed
+	if updated, err := s.entryFor(entry.Path); err == nil {
+		s.store.Upsert(updated)
+		entry = updated
 	}
 
+	etag := contentETag([]byte(req.Content))
+	w.Header().Set("ETag", etag)
 	writeJSON(w, map[string]interface{}{
 		"success": true,
 		"file":    entry,
+		"etag":    etag,
 	})
 }
 
-func (s *Server) deleteFile(w http.ResponseWriter, entry *models.FileEntry) {
+// writeConflict reports a failed If-Match with both versions, a diff from the
+// on-disk content to the submitted one and, if the client sent its base, a
+// three-way merge the UI can offer.
+func writeConflict(w http.ResponseWriter, etag, current string, req models.SaveRequest) {
+	conflict := models.SaveConflict{
+		Error:   "file changed on disk",
+		ETag:    etag,
+		Current: current,
+		Yours:   req.Content,
+		Base:    req.Base,
+		Diff:    diff.Lines(current, req.Content),
+	}
+	if req.Base != nil {
+		merge := diff.Merge3(*req.Base, req.Content, current)
+		conflict.Merge = &merge
+	}
+
+	w.Header().Set("ETag", etag)
+	writeJSONStatus(w, http.StatusConflict, conflict)
+}
+
+func (s *Server) deleteFile(w http.ResponseWriter, entry models.FileEntry, reason string) {
 	if entry.ReadOnly {
 		http.Error(w, "file is read-only", http.StatusForbidden)
 		return
 	}
 
-	if err := os.Remove(entry.Path); err != nil {
+	item, err := s.trash.Trash(entry.Path, &entry, deleteReason(reason))
+	if err != nil {
 		http.Error(w, "cannot delete file: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	// Remove from scan results
-	s.removeFile(entry.ID)
+	s.touch(entry.Path)
+	s.store.Remove(entry.ID)
+	s.index.Remove(entry.ID)
 
 	writeJSON(w, map[string]interface{}{
 		"success": true,
+		"trashId": item.ID,
 	})
 }
 
-// handleBulkDelete deletes multiple files at once.
-// POST /api/files/bulk-delete  {ids: ["id1","id2"]}
+// handleBulkDelete moves multiple files to the trash at once.
+// POST /api/files/bulk-delete  {ids: ["id1","id2"], reason: "cleanup"}
 func (s *Server) handleBulkDelete(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -199,12 +507,12 @@ func (s *Server) handleBulkDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var deleted []string
+	var deleted, paths []string
 	var errors []string
 
 	for _, id := range req.IDs {
-		entry := s.findFile(id)
-		if entry == nil {
+		entry, ok := s.store.Find(id)
+		if !ok {
 			errors = append(errors, id+": not found")
 			continue
 		}
@@ -212,17 +520,18 @@ func (s *Server) handleBulkDelete(w http.ResponseWriter, r *http.Request) {
 			errors = append(errors, entry.Name+": read-only")
 			continue
 		}
-		if err := os.Remove(entry.Path); err != nil {
+		if _, err := s.trash.Trash(entry.Path, &entry, deleteReason(req.Reason)); err != nil {
 			errors = append(errors, entry.Name+": "+err.Error())
 			continue
 		}
 		deleted = append(deleted, id)
+		paths = append(paths, entry.Path)
 	}
 
 	// Remove all deleted files from scan results
-	for _, id := range deleted {
-		s.removeFile(id)
-	}
+	s.touch(paths...)
+	s.store.Remove(deleted...)
+	s.index.Remove(deleted...)
 
 	writeJSON(w, map[string]interface{}{
 		"deleted": len(deleted),
@@ -230,123 +539,125 @@ func (s *Server) handleBulkDelete(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func (s *Server) removeFile(id string) {
-	files := s.result.Files
-	for i := range files {
-		if files[i].ID == id {
-			s.result.Files = append(files[:i], files[i+1:]...)
-			return
-		}
-	}
-}
-
-// handleRescan triggers a fresh scan.
+// handleRescan triggers a fresh scan or reports on the current one.
+// GET  /api/rescan  — progress of the running or most recent scan
+// POST /api/rescan  — scan again; the scan is abandoned if the client disconnects
 func (s *Server) handleRescan(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		writeJSON(w, s.progress.Load())
+		return
+	}
 	if r.Method != http.MethodPost {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	if err := s.refresh(); err != nil {
+	if err := s.refresh(r.Context()); err != nil {
 		http.Error(w, "scan failed: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
-	writeJSON(w, s.result)
+	s.events.publish(models.FileEvent{Type: models.EventRescan})
+	writeJSON(w, s.store.Snapshot())
 }
 
-// staleDays is the threshold after which a file is considered stale.
-const staleDays = 30
-
-// handleCleanup analyzes files and returns cleanup suggestions.
+// handleCleanup analyzes files against the cleanup rules and returns
+// suggestions, along with orphaned project directories.
+// GET /api/cleanup
 func (s *Server) handleCleanup(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	now := time.Now()
-	var items []models.CleanupItem
-	var totalSize int64
+	set, err := cleanup.Load(s.cleanupRules)
+	if err != nil {
+		http.Error(w, "invalid cleanup rules: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	engine, err := cleanup.Compile(set.Rules)
+	if err != nil {
+		http.Error(w, "invalid cleanup rules: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	files := s.store.Snapshot().Files
+	if engine.NeedsDuplicates() {
+		engine.SetDuplicates(dupes.Exact(files))
+	}
 
-	for _, f := range s.result.Files {
-		if f.ReadOnly {
-			continue
-		}
+	orphans, err := scanner.OrphanedProjects()
+	if err != nil {
+		log.Printf("Orphaned project detection failed: %v", err)
+	}
+	var orphanPrefixes []string
+	var totalSize int64
+	for _, o := range orphans {
+		orphanPrefixes = append(orphanPrefixes, o.Path+string(os.PathSeparator))
+		totalSize += o.Size
+	}
 
-		// Check for 0-byte files
-		if f.Size == 0 {
-			items = append(items, models.CleanupItem{
-				FileEntry:   f,
-				Reason:      models.ReasonEmptyFile,
-				ReasonLabel: "Empty file (0 bytes)",
-			})
-			totalSize += f.Size
-			continue
-		}
+	now := time.Now()
+	var items []models.CleanupItem
 
-		// Read content to check for empty-content patterns
-		data, err := os.ReadFile(f.Path)
-		if err != nil {
-			continue
-		}
-		trimmed := strings.TrimSpace(string(data))
-
-		if trimmed == "" || trimmed == "[]" || trimmed == "{}" || trimmed == "null" {
-			label := "Empty content"
-			switch trimmed {
-			case "[]":
-				label = "Empty array ([])"
-			case "{}":
-				label = "Empty object ({})"
-			case "null":
-				label = "Null content"
-			case "":
-				label = "Blank file (whitespace only)"
-			}
-			items = append(items, models.CleanupItem{
-				FileEntry:   f,
-				Reason:      models.ReasonEmptyContent,
-				ReasonLabel: label,
-			})
-			totalSize += f.Size
+	for _, f := range files {
+		if f.ReadOnly || hasAnyPrefix(f.Path, orphanPrefixes) {
 			continue
 		}
 
-		// Check staleness
-		days := int(now.Sub(f.ModTime).Hours() / 24)
-		if days >= staleDays {
-			items = append(items, models.CleanupItem{
-				FileEntry:   f,
-				Reason:      models.ReasonStale,
-				ReasonLabel: fmt.Sprintf("Not modified in %d days", days),
-				DaysSince:   days,
-			})
+		if item, ok := engine.Evaluate(f, now); ok {
+			items = append(items, item)
 			totalSize += f.Size
 		}
 	}
 
+	if orphans == nil {
+		orphans = []models.OrphanedProject{}
+	}
 	writeJSON(w, models.CleanupResult{
 		Items:      items,
+		Orphans:    orphans,
 		TotalSize:  totalSize,
-		TotalCount: len(items),
+		TotalCount: len(items) + len(orphans),
 	})
 }
 
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
 // handleCategories returns the category definitions.
 func (s *Server) handleCategories(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, models.AllCategories())
 }
 
-func (s *Server) findFile(id string) *models.FileEntry {
-	for i := range s.result.Files {
-		if s.result.Files[i].ID == id {
-			return &s.result.Files[i]
+// contentETag returns a strong ETag derived from the file content.
+func contentETag(data []byte) string {
+	h := sha256.Sum256(data)
+	return fmt.Sprintf("\"%x\"", h[:16])
+}
+
+// etagMatches reports whether an If-Match header value matches etag.
+// It accepts "*", lists of tags and weak (W/) tags.
+func etagMatches(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
 		}
 	}
-	return nil
+	return false
 }
 
 func writeJSON(w http.ResponseWriter, data interface{}) {
+	writeJSONStatus(w, http.StatusOK, data)
+}
+
+func writeJSONStatus(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(data); err != nil {
 		log.Printf("JSON encode error: %v", err)
 	}
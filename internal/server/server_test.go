@@ -0,0 +1,207 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/history"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/scanner"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/trash"
+)
+
+// newTestServer serves a scan of a temporary home directory holding n
+// project CLAUDE.md files.
+func newTestServer(t *testing.T, n int) *httptest.Server {
+	t.Helper()
+	srv, _ := newScannedServer(t, n)
+	ts := httptest.NewServer(srv.handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// newScannedServer is newTestServer without HTTP. It also returns the home
+// directory; project i is at projects/pNN below it.
+func newScannedServer(t *testing.T, n int) (*Server, string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	for i := 0; i < n; i++ {
+		dir := filepath.Join(home, "projects", fmt.Sprintf("p%02d", i))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "CLAUDE.md"), []byte("# original\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sc, err := scanner.New(nil, scanner.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := t.TempDir()
+	srv := New(0, sc, fstest.MapFS{}, Options{
+		Trash:        trash.New(filepath.Join(data, "trash"), 0),
+		History:      history.New(filepath.Join(data, "history")),
+		CleanupRules: filepath.Join(data, "cleanup-rules.json"),
+	})
+	if err := srv.refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return srv, home
+}
+
+func listFiles(t *testing.T, ts *httptest.Server) []models.FileEntry {
+	t.Helper()
+	resp, err := http.Get(ts.URL + "/api/files")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var files []models.FileEntry
+	if err := json.NewDecoder(resp.Body).Decode(&files); err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+// TestConcurrentRequests rescans, bulk-deletes and saves at the same time.
+// Run it with -race.
+func TestConcurrentRequests(t *testing.T) {
+	const n = 20
+	ts := newTestServer(t, n)
+	files := listFiles(t, ts)
+	if len(files) != n {
+		t.Fatalf("scanned %d files, want %d", len(files), n)
+	}
+	toDelete, toSave := files[:n/2], files[n/2:]
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 3*n)
+	do := func(method, path string, header http.Header, body any) (*http.Response, error) {
+		var buf bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&buf).Encode(body)
+		}
+		req, _ := http.NewRequest(method, ts.URL+path, &buf)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		return http.DefaultClient.Do(req)
+	}
+	expect := func(what string, resp *http.Response, err error) {
+		if err != nil {
+			errs <- fmt.Errorf("%s: %v", what, err)
+			return
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			errs <- fmt.Errorf("%s: status %d", what, resp.StatusCode)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				resp, err := do(http.MethodPost, "/api/rescan", nil, nil)
+				expect("rescan", resp, err)
+			}
+		}()
+	}
+
+	for _, f := range toDelete {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			resp, err := do(http.MethodPost, "/api/files/bulk-delete", nil, models.BulkDeleteRequest{IDs: []string{id}})
+			expect("bulk-delete "+id, resp, err)
+		}(f.ID)
+	}
+
+	for _, f := range toSave {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			resp, err := http.Get(ts.URL + "/api/files/" + id)
+			if err != nil {
+				errs <- err
+				return
+			}
+			resp.Body.Close()
+			header := http.Header{"If-Match": {resp.Header.Get("ETag")}}
+			resp, err = do(http.MethodPut, "/api/files/"+id, header, models.SaveRequest{Content: "# saved " + id + "\n"})
+			expect("save "+id, resp, err)
+		}(f.ID)
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	remaining := make(map[string]bool)
+	for _, f := range listFiles(t, ts) {
+		remaining[f.ID] = true
+	}
+	for _, f := range toDelete {
+		if remaining[f.ID] {
+			t.Errorf("%s still listed after bulk-delete", f.RelPath)
+		}
+		if _, err := os.Stat(f.Path); !os.IsNotExist(err) {
+			t.Errorf("%s still on disk after bulk-delete", f.RelPath)
+		}
+	}
+	for _, f := range toSave {
+		if !remaining[f.ID] {
+			t.Errorf("%s missing after save", f.RelPath)
+		}
+		data, err := os.ReadFile(f.Path)
+		if err != nil || !strings.HasPrefix(string(data), "# saved") {
+			t.Errorf("%s = %q, %v; want saved content", f.RelPath, data, err)
+		}
+	}
+}
+
+// TestRefreshIndex checks that a rescan updates the search index for changed
+// and deleted files.
+func TestRefreshIndex(t *testing.T) {
+	srv, home := newScannedServer(t, 3)
+	changed := filepath.Join(home, "projects", "p00", "CLAUDE.md")
+	if err := os.WriteFile(changed, []byte("# rewritten\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(changed, later, later)
+	if err := os.Remove(filepath.Join(home, "projects", "p01", "CLAUDE.md")); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for query, want := range map[string]int{"rewritten": 1, "original": 1} {
+		hits, err := srv.index.Search(query, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(hits) != want {
+			t.Errorf("search for %q found %d files, want %d", query, len(hits), want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/sessions"
+)
+
+const (
+	defaultSessionLimit = 50
+	defaultTurnLimit    = 100
+)
+
+// handleSessions lists conversation transcripts, newest first.
+// GET /api/sessions?project=app&offset=0&limit=50
+//
+// project matches either the project name or the encoded directory name
+// under ~/.claude/projects.
+func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	offset, ok := intParam(w, q.Get("offset"), "offset", 0)
+	if !ok {
+		return
+	}
+	limit, ok := intParam(w, q.Get("limit"), "limit", defaultSessionLimit)
+	if !ok {
+		return
+	}
+	project := q.Get("project")
+
+	all, err := s.sessions.List()
+	if err != nil {
+		http.Error(w, "cannot list sessions: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	list := models.SessionList{Sessions: []models.SessionSummary{}, Offset: offset, Limit: limit}
+	for _, sess := range all {
+		if project != "" && sess.Project != project && sess.ProjectDir != project {
+			continue
+		}
+		if list.Total >= offset && len(list.Sessions) < limit {
+			list.Sessions = append(list.Sessions, sess)
+		}
+		list.Total++
+	}
+	writeJSON(w, list)
+}
+
+// handleSessionByID serves a single session.
+// GET /api/sessions/{id}                          — summary
+// GET /api/sessions/{id}/turns?cursor=0&limit=100 — messages as NDJSON
+//
+// Turns are streamed one JSON object per line. Each carries a cursor; pass
+// the last one back to read the next page. limit=0 streams to the end.
+func (s *Server) handleSessionByID(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	rest := strings.TrimPrefix(r.URL.Path, "/api/sessions/")
+	id, sub, _ := strings.Cut(rest, "/")
+	switch sub {
+	case "":
+		sess, err := s.sessions.Get(id)
+		if err != nil {
+			writeSessionError(w, err)
+			return
+		}
+		writeJSON(w, sess)
+	case "turns":
+		s.streamTurns(w, r, id)
+	default:
+		http.Error(w, "not found", http.StatusNotFound)
+	}
+}
+
+func (s *Server) streamTurns(w http.ResponseWriter, r *http.Request, id string) {
+	q := r.URL.Query()
+	cursor, ok := intParam(w, q.Get("cursor"), "cursor", 0)
+	if !ok {
+		return
+	}
+	limit, ok := intParam(w, q.Get("limit"), "limit", defaultTurnLimit)
+	if !ok {
+		return
+	}
+	if err := s.sessions.Check(id); err != nil {
+		writeSessionError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+	n := 0
+	err := s.sessions.Turns(id, int64(cursor), limit, func(t models.SessionTurn) error {
+		if err := enc.Encode(t); err != nil {
+			return err
+		}
+		if n++; flusher != nil && n%50 == 0 {
+			flusher.Flush()
+		}
+		return r.Context().Err()
+	})
+	if err != nil && r.Context().Err() == nil {
+		log.Printf("Streaming session %s failed: %v", id, err)
+	}
+}
+
+// intParam parses a non-negative integer query parameter, writing a 400
+// response and returning false if it is malformed.
+func intParam(w http.ResponseWriter, value, name string, def int) (int, bool) {
+	if value == "" {
+		return def, true
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		http.Error(w, "invalid "+name, http.StatusBadRequest)
+		return 0, false
+	}
+	return n, true
+}
+
+func writeSessionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, sessions.ErrNotFound):
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	case errors.Is(err, sessions.ErrAmbiguous):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
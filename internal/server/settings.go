@@ -0,0 +1,62 @@
+package server
+
+import (
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/scanner"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/settings"
+)
+
+// handleProjectByName routes per-project endpoints.
+// GET /api/projects/{name}/effective-settings
+func (s *Server) handleProjectByName(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/api/projects/")
+	name, sub, _ := strings.Cut(rest, "/")
+	if name == "" {
+		http.Error(w, "missing project name", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case sub == "effective-settings" && r.Method == http.MethodGet:
+		s.effectiveSettings(w, name)
+	case sub == "effective-settings":
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.Error(w, "not found", http.StatusNotFound)
+	}
+}
+
+// effectiveSettings merges the settings hierarchy for a project and reports,
+// for every key, which file set it and which values it overrode.
+func (s *Server) effectiveSettings(w http.ResponseWriter, ref string) {
+	root, status, err := s.resolveProject(ref)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	sources, effective, prov := settings.Resolve(settings.Layers(scanner.GlobalClaudeDir(), root))
+	if effective == nil {
+		effective = map[string]interface{}{}
+	}
+	if prov == nil {
+		prov = []models.SettingProvenance{}
+	}
+	project := models.Project{Name: filepath.Base(root), Path: root}
+	for _, p := range scanner.Projects(s.store.Snapshot().Files) {
+		if p.Path == root {
+			project = p
+			break
+		}
+	}
+	writeJSON(w, models.EffectiveSettings{
+		Project:    project,
+		Sources:    sources,
+		Effective:  effective,
+		Provenance: prov,
+	})
+}
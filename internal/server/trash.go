@@ -0,0 +1,139 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/trash"
+)
+
+// deleteReason returns the reason recorded for a trashed file.
+func deleteReason(reason string) string {
+	if reason == "" {
+		return "manual"
+	}
+	return reason
+}
+
+// handleTrash lists trashed items.
+// GET /api/trash
+func (s *Server) handleTrash(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	items, err := s.trash.List()
+	if err != nil {
+		http.Error(w, "cannot read trash: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	listing := models.TrashListing{
+		Items:         items,
+		RetentionDays: int(s.trash.Retention().Hours() / 24),
+	}
+	if listing.Items == nil {
+		listing.Items = []models.TrashItem{}
+	}
+	for _, item := range items {
+		listing.TotalSize += item.Size
+	}
+	writeJSON(w, listing)
+}
+
+// handleTrashItem restores or purges a single trashed item.
+// POST   /api/trash/{id}/restore
+// DELETE /api/trash/{id}
+func (s *Server) handleTrashItem(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/api/trash/")
+	id, action, _ := strings.Cut(rest, "/")
+	if id == "" {
+		http.Error(w, "missing trash id", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case action == "restore" && r.Method == http.MethodPost:
+		s.restoreTrashItem(w, id)
+	case action == "" && r.Method == http.MethodDelete:
+		if err := s.trash.Purge(id); err != nil {
+			writeTrashError(w, err)
+			return
+		}
+		writeJSON(w, map[string]interface{}{"success": true})
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) restoreTrashItem(w http.ResponseWriter, id string) {
+	item, err := s.trash.Restore(id)
+	if err != nil {
+		writeTrashError(w, err)
+		return
+	}
+
+	// Directories are picked up on the next rescan; files are re-added now.
+	resp := map[string]interface{}{"success": true, "item": item}
+	s.touch(item.OriginalPath)
+	if !item.IsDir {
+		if entry, err := s.entryFor(item.OriginalPath); err == nil {
+			s.store.Upsert(entry)
+			s.reindex(entry)
+			resp["file"] = entry
+		}
+	}
+	writeJSON(w, resp)
+}
+
+// handleTrashPurge permanently deletes trashed items.
+// POST /api/trash/purge  {all: true} | {olderThanDays: 7} | {} (apply retention)
+func (s *Server) handleTrashPurge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.TrashPurgeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var purged int
+	var err error
+	switch {
+	case req.All:
+		purged, err = s.trash.PurgeOlderThan(0)
+	case req.OlderThanDays != nil:
+		if *req.OlderThanDays < 0 {
+			http.Error(w, "olderThanDays must not be negative", http.StatusBadRequest)
+			return
+		}
+		purged, err = s.trash.PurgeOlderThan(time.Duration(*req.OlderThanDays) * 24 * time.Hour)
+	default:
+		purged, err = s.trash.PurgeExpired()
+	}
+	if err != nil {
+		http.Error(w, "purge failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, map[string]interface{}{"purged": purged})
+}
+
+func writeTrashError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, trash.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, trash.ErrTargetExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
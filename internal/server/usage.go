@@ -0,0 +1,59 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/usage"
+)
+
+// handleUsage reports token usage and estimated cost from session transcripts.
+// GET /api/usage?from=2025-01-01&to=2025-01-31
+// GET /api/usage?format=csv&group=session|project|model|day
+//
+// from and to are inclusive local dates. CSV export returns one group,
+// by day unless group is given.
+func (s *Server) handleUsage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	from, to := q.Get("from"), q.Get("to")
+	for _, d := range []string{from, to} {
+		if _, err := time.Parse("2006-01-02", d); d != "" && err != nil {
+			http.Error(w, "invalid date "+d+", expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+	}
+
+	records, err := s.sessions.Usage()
+	if err != nil {
+		http.Error(w, "cannot read sessions: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	report := usage.Report(records, from, to)
+
+	switch q.Get("format") {
+	case "", "json":
+		writeJSON(w, report)
+	case "csv":
+		group := q.Get("group")
+		if group == "" {
+			group = usage.GroupDay
+		}
+		rows, ok := usage.Rows(report, group)
+		if !ok {
+			http.Error(w, "invalid group", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="claude-usage-by-`+group+`.csv"`)
+		if err := usage.WriteCSV(w, group, rows); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	default:
+		http.Error(w, "invalid format", http.StatusBadRequest)
+	}
+}
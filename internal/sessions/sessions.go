@@ -0,0 +1,397 @@
+// Package sessions indexes Claude Code conversation transcripts stored as
+// JSONL under ~/.claude/projects/<encoded project>/<session id>.jsonl.
+package sessions
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/scanner"
+)
+
+// maxPromptLen caps the first prompt kept in a summary, in runes.
+const maxPromptLen = 200
+
+// Errors returned for session IDs that do not resolve to one transcript.
+var (
+	ErrNotFound  = errors.New("session not found")
+	ErrAmbiguous = errors.New("session ID matches transcripts in more than one project")
+)
+
+// validID matches session IDs: UUIDs, or names like "agent-1a2b3c" for
+// subagent transcripts. Anything else, such as glob metacharacters or path
+// separators, cannot name a transcript.
+var validID = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// Index keeps a summary of every transcript below a projects directory.
+// Summaries are cached by file size and modification time; since transcripts
+// are append-only, a grown file is summarised from where the last pass ended.
+type Index struct {
+	dir   string
+	mu    sync.Mutex
+	cache map[string]*summaryState // keyed by transcript path
+}
+
+// summaryState is a cached summary plus what is needed to extend it.
+type summaryState struct {
+	summary       models.SessionSummary
+	modTime       time.Time
+	offset        int64 // bytes summarised so far
+	tools         map[string]bool
+	models        map[string]bool
+	lastMessageID string
+
+	// Token usage by day and model. The last message's usage is remembered
+	// so that a repeat of it (one line per content block) replaces it.
+	usage     map[usageKey]*usageTotal
+	usageMsg  string
+	usageAt   usageKey
+	usageLast models.TokenUsage
+}
+
+type usageKey struct {
+	date  string
+	model string
+}
+
+type usageTotal struct {
+	messages int
+	tokens   models.TokenUsage
+}
+
+// New creates an index of the transcripts below dir (normally ~/.claude/projects).
+func New(dir string) *Index {
+	return &Index{dir: dir, cache: make(map[string]*summaryState)}
+}
+
+// List returns summaries of all sessions, newest first.
+func (x *Index) List() ([]models.SessionSummary, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	states, err := x.refreshAll()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]models.SessionSummary, len(states))
+	for i, st := range states {
+		out[i] = st.summary
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].StartedAt.Equal(out[j].StartedAt) {
+			return out[i].StartedAt.After(out[j].StartedAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+// Usage returns the token usage of all sessions, one record per session,
+// day and model.
+func (x *Index) Usage() ([]models.UsageRecord, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	states, err := x.refreshAll()
+	if err != nil {
+		return nil, err
+	}
+	var out []models.UsageRecord
+	for _, st := range states {
+		projectID := st.summary.ProjectPath
+		if projectID == "" {
+			projectID = st.summary.ProjectDir
+		}
+		for key, t := range st.usage {
+			out = append(out, models.UsageRecord{
+				SessionID:  st.summary.ID,
+				Project:    st.summary.Project,
+				ProjectID:  projectID,
+				Date:       key.date,
+				Model:      key.model,
+				Messages:   t.messages,
+				TokenUsage: t.tokens,
+			})
+		}
+	}
+	return out, nil
+}
+
+// Get returns the summary of one session.
+func (x *Index) Get(id string) (models.SessionSummary, error) {
+	p, err := x.path(id)
+	if err != nil {
+		return models.SessionSummary{}, err
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	st, err := x.refresh(p)
+	if err != nil {
+		return models.SessionSummary{}, err
+	}
+	return st.summary, nil
+}
+
+// Check reports whether id names exactly one transcript, returning
+// ErrNotFound or ErrAmbiguous if not. Unlike Get, it does not read it.
+func (x *Index) Check(id string) error {
+	_, err := x.path(id)
+	return err
+}
+
+// Turns reads the messages of a session starting at byte offset cursor (0 for
+// the beginning, otherwise a Cursor from a previously returned turn), calling
+// fn for each until limit turns were read (0 means no limit) or fn fails.
+// The transcript is streamed, never loaded whole.
+func (x *Index) Turns(id string, cursor int64, limit int, fn func(models.SessionTurn) error) error {
+	p, err := x.path(id)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Seek(cursor, io.SeekStart); err != nil {
+		return err
+	}
+
+	n := 0
+	_, err = scanRecords(f, cursor, func(rec *record, next int64) error {
+		if !rec.isTurn() {
+			return nil
+		}
+		if err := fn(rec.turn(next)); err != nil {
+			return err
+		}
+		if n++; limit > 0 && n >= limit {
+			return errStop
+		}
+		return nil
+	})
+	return err
+}
+
+// path resolves a session ID to its transcript.
+func (x *Index) path(id string) (string, error) {
+	if !validID.MatchString(id) {
+		return "", ErrNotFound
+	}
+	matches, err := filepath.Glob(filepath.Join(x.dir, "*", id+".jsonl"))
+	switch {
+	case err != nil || len(matches) == 0:
+		return "", ErrNotFound
+	case len(matches) > 1:
+		return "", ErrAmbiguous
+	}
+	return matches[0], nil
+}
+
+// refreshAll brings every transcript's summary up to date and drops those of
+// deleted transcripts. Must hold mu.
+func (x *Index) refreshAll() ([]*summaryState, error) {
+	paths, err := filepath.Glob(filepath.Join(x.dir, "*", "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(paths))
+	var states []*summaryState
+	for _, p := range paths {
+		seen[p] = true
+		st, err := x.refresh(p)
+		if err != nil {
+			continue // unreadable transcript
+		}
+		states = append(states, st)
+	}
+	for p := range x.cache {
+		if !seen[p] {
+			delete(x.cache, p)
+		}
+	}
+	return states, nil
+}
+
+// refresh returns the up-to-date summary of the transcript at p. Must hold mu.
+func (x *Index) refresh(p string) (*summaryState, error) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, err
+	}
+
+	st := x.cache[p]
+	switch {
+	case st != nil && info.Size() == st.summary.Size && info.ModTime().Equal(st.modTime):
+		return st, nil
+	case st != nil && info.Size() > st.summary.Size:
+		// Appended to: extend a copy, so a failed read leaves the cached
+		// summary matching its offset.
+		st = st.clone()
+	default:
+		// New or rewritten transcript: start over.
+		projectDir := filepath.Base(filepath.Dir(p))
+		name, projectPath := scanner.ProjectFromEncoded(projectDir)
+		st = &summaryState{
+			summary: models.SessionSummary{
+				ID:          strings.TrimSuffix(filepath.Base(p), ".jsonl"),
+				Project:     name,
+				ProjectPath: projectPath,
+				ProjectDir:  projectDir,
+				Path:        p,
+				Tools:       []string{},
+				Models:      []string{},
+			},
+			tools:  make(map[string]bool),
+			models: make(map[string]bool),
+			usage:  make(map[usageKey]*usageTotal),
+		}
+	}
+
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(st.offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	off, err := scanRecords(f, st.offset, func(rec *record, _ int64) error {
+		st.add(rec)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	st.offset = off
+	st.modTime = info.ModTime()
+	st.summary.Size = info.Size()
+	st.summary.Tools = sortedKeys(st.tools)
+	st.summary.Models = sortedKeys(st.models)
+	x.cache[p] = st
+	return st, nil
+}
+
+// clone returns a copy of st that can be extended without changing st.
+func (st *summaryState) clone() *summaryState {
+	c := *st
+	c.tools = make(map[string]bool, len(st.tools))
+	for k := range st.tools {
+		c.tools[k] = true
+	}
+	c.models = make(map[string]bool, len(st.models))
+	for k := range st.models {
+		c.models[k] = true
+	}
+	c.usage = make(map[usageKey]*usageTotal, len(st.usage))
+	for k, t := range st.usage {
+		total := *t
+		c.usage[k] = &total
+	}
+	return &c
+}
+
+// add folds one transcript record into the summary.
+func (st *summaryState) add(rec *record) {
+	s := &st.summary
+	if t := rec.time(); !t.IsZero() {
+		if s.StartedAt.IsZero() || t.Before(s.StartedAt) {
+			s.StartedAt = t
+		}
+		if t.After(s.EndedAt) {
+			s.EndedAt = t
+		}
+	}
+	if rec.Cwd != "" && s.Cwd == "" {
+		s.Cwd = rec.Cwd
+	}
+	if !rec.isTurn() {
+		return
+	}
+
+	text, tools, toolResult := rec.Message.content()
+	for _, t := range tools {
+		st.tools[t] = true
+	}
+	if m := rec.Message.Model; m != "" && !strings.HasPrefix(m, "<") {
+		st.models[m] = true // "<synthetic>" marks locally generated messages
+		if rec.Message.Usage != nil {
+			st.addUsage(rec)
+		}
+	}
+
+	// Claude Code writes each content block of an assistant message as its
+	// own line sharing the message ID; count the message once.
+	if rec.Type == "assistant" && rec.Message.ID != "" {
+		if rec.Message.ID == st.lastMessageID {
+			return
+		}
+		st.lastMessageID = rec.Message.ID
+	}
+	if rec.IsMeta {
+		return
+	}
+	s.MessageCount++
+	if s.FirstPrompt == "" && rec.Type == "user" && !toolResult && !rec.IsSidechain {
+		s.FirstPrompt = truncate(strings.TrimSpace(text), maxPromptLen)
+	}
+}
+
+// addUsage records the token usage of an assistant message.
+func (st *summaryState) addUsage(rec *record) {
+	tokens := rec.Message.Usage.tokens()
+	if rec.Message.ID != "" && rec.Message.ID == st.usageMsg {
+		t := st.usage[st.usageAt]
+		t.tokens.Add(models.TokenUsage{
+			Input:         tokens.Input - st.usageLast.Input,
+			Output:        tokens.Output - st.usageLast.Output,
+			CacheCreation: tokens.CacheCreation - st.usageLast.CacheCreation,
+			CacheRead:     tokens.CacheRead - st.usageLast.CacheRead,
+		})
+		st.usageLast = tokens
+		return
+	}
+
+	at := rec.time()
+	if at.IsZero() {
+		return // no day to count it under
+	}
+	key := usageKey{at.Local().Format("2006-01-02"), rec.Message.Model}
+	t := st.usage[key]
+	if t == nil {
+		t = &usageTotal{}
+		st.usage[key] = t
+	}
+	t.messages++
+	t.tokens.Add(tokens)
+	st.usageMsg, st.usageAt, st.usageLast = rec.Message.ID, key, tokens
+}
+
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n]) + "…"
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
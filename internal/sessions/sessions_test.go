@@ -0,0 +1,112 @@
+package sessions
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPath(t *testing.T) {
+	dir := t.TempDir()
+	for _, p := range []string{
+		"-home-u-app/1b9e4f52-6c1d-4f0e-9a51-0d2c3f6b7a10.jsonl",
+		"-home-u-app/agent-7f3a.jsonl",
+		"-home-u-work-api/dup.jsonl",
+		"-home-u-personal-api/dup.jsonl",
+	} {
+		path := filepath.Join(dir, p)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	x := New(dir)
+
+	tests := []struct {
+		id      string
+		want    string
+		wantErr error
+	}{
+		{"1b9e4f52-6c1d-4f0e-9a51-0d2c3f6b7a10", "-home-u-app/1b9e4f52-6c1d-4f0e-9a51-0d2c3f6b7a10.jsonl", nil},
+		{"agent-7f3a", "-home-u-app/agent-7f3a.jsonl", nil},
+		{"missing", "", ErrNotFound},
+		{"dup", "", ErrAmbiguous},
+		{"", "", ErrNotFound},
+		{"*", "", ErrNotFound},
+		{"[a-z]*", "", ErrNotFound},
+		{"agent-?f3a", "", ErrNotFound},
+		{"../-home-u-app/agent-7f3a", "", ErrNotFound},
+	}
+	for _, tt := range tests {
+		got, err := x.path(tt.id)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("path(%q) error = %v, want %v", tt.id, err, tt.wantErr)
+			continue
+		}
+		if tt.want != "" && got != filepath.Join(dir, tt.want) {
+			t.Errorf("path(%q) = %q, want %q", tt.id, got, filepath.Join(dir, tt.want))
+		}
+	}
+}
+
+// transcript lines: a prompt, and an assistant reply written as two content
+// blocks sharing a message ID, the second without a valid timestamp.
+const (
+	promptLine = `{"type":"user","timestamp":"2025-03-01T10:00:00Z","message":{"role":"user","content":"hello"}}` + "\n"
+	replyLine  = `{"type":"assistant","timestamp":"2025-03-01T10:00:05Z","message":{"id":"m1","model":"claude-x","content":[{"type":"text","text":"hi"}],"usage":{"input_tokens":10,"output_tokens":5}}}` + "\n"
+	undatedMsg = `{"type":"assistant","timestamp":"soon","message":{"id":"m2","model":"claude-x","content":[{"type":"text","text":"?"}],"usage":{"input_tokens":7,"output_tokens":7}}}` + "\n"
+)
+
+func writeTranscript(t *testing.T, dir, content string) {
+	t.Helper()
+	path := filepath.Join(dir, "-home-u-app", "s1.jsonl")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRefreshAppend(t *testing.T) {
+	dir := t.TempDir()
+	writeTranscript(t, dir, promptLine)
+	x := New(dir)
+	if _, err := x.Get("s1"); err != nil {
+		t.Fatal(err)
+	}
+	cached := x.cache[filepath.Join(dir, "-home-u-app", "s1.jsonl")]
+	before := cached.summary
+
+	writeTranscript(t, dir, promptLine+replyLine)
+	got, err := x.Get("s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.MessageCount != 2 || len(got.Models) != 1 {
+		t.Errorf("after append: %d messages, models %v; want 2 and [claude-x]", got.MessageCount, got.Models)
+	}
+	if cached.summary.MessageCount != before.MessageCount || len(cached.models) != 0 {
+		t.Error("extending the summary changed the previously cached state")
+	}
+}
+
+func TestUsageSkipsUndated(t *testing.T) {
+	dir := t.TempDir()
+	writeTranscript(t, dir, promptLine+replyLine+undatedMsg)
+	recs, err := New(dir).Usage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 1 {
+		t.Fatalf("got %d usage records, want 1: %+v", len(recs), recs)
+	}
+	day := time.Date(2025, 3, 1, 10, 0, 5, 0, time.UTC).Local().Format("2006-01-02")
+	if r := recs[0]; r.Date != day || r.Messages != 1 || r.TokenUsage.Input != 10 {
+		t.Errorf("usage record = %+v, want one message of 10 input tokens on %s", r, day)
+	}
+}
@@ -0,0 +1,151 @@
+package sessions
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+)
+
+// record is the subset of a transcript line ClaudeShelf reads. Lines are
+// decoded one at a time, so memory use is bounded by the longest line rather
+// than the size of the transcript.
+type record struct {
+	Type        string   `json:"type"` // "user", "assistant", "summary", "system", ...
+	UUID        string   `json:"uuid"`
+	Timestamp   string   `json:"timestamp"`
+	Cwd         string   `json:"cwd"`
+	IsMeta      bool     `json:"isMeta"`
+	IsSidechain bool     `json:"isSidechain"`
+	Message     *message `json:"message"`
+}
+
+type message struct {
+	ID      string          `json:"id"`
+	Role    string          `json:"role"`
+	Model   string          `json:"model"`
+	Content json.RawMessage `json:"content"`
+	Usage   *usage          `json:"usage"`
+}
+
+// usage is the token accounting the API returns with each assistant message.
+type usage struct {
+	InputTokens              int64 `json:"input_tokens"`
+	OutputTokens             int64 `json:"output_tokens"`
+	CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+}
+
+func (u *usage) tokens() models.TokenUsage {
+	return models.TokenUsage{
+		Input:         u.InputTokens,
+		Output:        u.OutputTokens,
+		CacheCreation: u.CacheCreationInputTokens,
+		CacheRead:     u.CacheReadInputTokens,
+	}
+}
+
+type contentBlock struct {
+	Type string `json:"type"` // "text", "tool_use", "tool_result", "thinking", "image"
+	Text string `json:"text"`
+	Name string `json:"name"`
+}
+
+// isTurn reports whether the record is a user or assistant message.
+func (r *record) isTurn() bool {
+	return (r.Type == "user" || r.Type == "assistant") && r.Message != nil
+}
+
+func (r *record) time() time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, r.Timestamp)
+	return t
+}
+
+// content returns the message text, the tools it invokes, and whether it
+// consists only of tool results.
+func (m *message) content() (text string, tools []string, toolResult bool) {
+	if len(m.Content) == 0 {
+		return "", nil, false
+	}
+	var s string
+	if json.Unmarshal(m.Content, &s) == nil {
+		return s, nil, false
+	}
+
+	var blocks []contentBlock
+	if json.Unmarshal(m.Content, &blocks) != nil {
+		return "", nil, false
+	}
+	var texts []string
+	results := 0
+	for _, b := range blocks {
+		switch b.Type {
+		case "text":
+			texts = append(texts, b.Text)
+		case "tool_use":
+			tools = append(tools, b.Name)
+		case "tool_result":
+			results++
+		}
+	}
+	return strings.Join(texts, "\n\n"), tools, results > 0 && results == len(blocks)
+}
+
+// turn converts a message record into a SessionTurn.
+func (r *record) turn(next int64) models.SessionTurn {
+	text, tools, toolResult := r.Message.content()
+	return models.SessionTurn{
+		UUID:        r.UUID,
+		Role:        r.Type,
+		Timestamp:   r.time(),
+		Model:       r.Message.Model,
+		Text:        text,
+		Tools:       tools,
+		ToolResult:  toolResult,
+		IsMeta:      r.IsMeta,
+		IsSidechain: r.IsSidechain,
+		Cursor:      next,
+	}
+}
+
+// errStop ends scanRecords early without reporting an error.
+var errStop = errors.New("stop")
+
+// scanRecords reads newline-terminated records from r, which is positioned at
+// byte offset off, calling fn with each record and the offset just past it.
+// Lines that are not valid JSON are skipped. A trailing line without a
+// newline is left unread, as Claude Code may still be writing it. It returns
+// the offset after the last complete line consumed.
+func scanRecords(r io.Reader, off int64, fn func(rec *record, next int64) error) (int64, error) {
+	br := bufio.NewReaderSize(r, 64<<10)
+	for {
+		line, err := br.ReadBytes('\n')
+		if err == io.EOF {
+			return off, nil
+		}
+		if err != nil {
+			return off, err
+		}
+		off += int64(len(line))
+
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var rec record
+		if json.Unmarshal(line, &rec) != nil {
+			continue
+		}
+		if err := fn(&rec, off); err != nil {
+			if err == errStop {
+				return off, nil
+			}
+			return off, err
+		}
+	}
+}
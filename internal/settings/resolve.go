@@ -0,0 +1,224 @@
+package settings
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+)
+
+// Settings scopes, lowest precedence first.
+const (
+	ScopeUser    = "user"
+	ScopeProject = "project"
+	ScopeLocal   = "local"
+	ScopeManaged = "managed"
+)
+
+// Layer is one settings file taking part in resolution.
+type Layer struct {
+	Scope string
+	Path  string
+}
+
+// Layers returns the settings files Claude Code reads for a project, from
+// lowest to highest precedence: the user's ~/.claude/settings.json, the
+// shared project .claude/settings.json, the uncommitted
+// .claude/settings.local.json and finally enterprise managed settings.
+func Layers(globalClaudeDir, projectRoot string) []Layer {
+	return []Layer{
+		{ScopeUser, filepath.Join(globalClaudeDir, "settings.json")},
+		{ScopeProject, filepath.Join(projectRoot, ".claude", "settings.json")},
+		{ScopeLocal, filepath.Join(projectRoot, ".claude", "settings.local.json")},
+		{ScopeManaged, managedSettingsPath()},
+	}
+}
+
+// managedSettingsPath returns the platform's enterprise policy file.
+func managedSettingsPath() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "/Library/Application Support/ClaudeCode/managed-settings.json"
+	case "windows":
+		return `C:\ProgramData\ClaudeCode\managed-settings.json`
+	default:
+		return "/etc/claude-code/managed-settings.json"
+	}
+}
+
+// node tracks a merged value and its provenance while layers are applied.
+type node struct {
+	source     string
+	value      interface{}           // scalar value, nil for objects/arrays
+	children   map[string]*node      // set for objects
+	items      []models.SettingValue // set for arrays
+	isArray    bool
+	overridden []models.SettingValue
+}
+
+// Resolve merges the given layers. Objects are merged key by key, arrays are
+// concatenated across scopes without duplicates, and any other value from a
+// higher-precedence scope replaces the lower one. Missing files are skipped;
+// unparseable files are reported in the sources and otherwise ignored.
+func Resolve(layers []Layer) ([]models.SettingsSource, map[string]interface{}, []models.SettingProvenance) {
+	root := &node{children: map[string]*node{}}
+	var sources []models.SettingsSource
+
+	for _, l := range layers {
+		src := models.SettingsSource{Scope: l.Scope, Path: l.Path}
+		data, err := os.ReadFile(l.Path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				src.Error = err.Error()
+			}
+			sources = append(sources, src)
+			continue
+		}
+		src.Exists = true
+
+		obj, err := decodeObject(data)
+		if err != nil {
+			src.Error = err.Error()
+			sources = append(sources, src)
+			continue
+		}
+		sources = append(sources, src)
+		mergeObject(root, obj, l.Scope)
+	}
+
+	var prov []models.SettingProvenance
+	effective, _ := materialize(root, "", &prov).(map[string]interface{})
+	return sources, effective, prov
+}
+
+func decodeObject(data []byte) (map[string]interface{}, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var obj map[string]interface{}
+	if err := dec.Decode(&obj); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return obj, nil
+}
+
+func mergeObject(dst *node, obj map[string]interface{}, scope string) {
+	for k, v := range obj {
+		child := dst.children[k]
+		if child == nil {
+			child = &node{}
+			dst.children[k] = child
+		}
+		mergeValue(child, v, scope)
+	}
+}
+
+func mergeValue(n *node, v interface{}, scope string) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		if n.children == nil {
+			n.recordOverride()
+			*n = node{children: map[string]*node{}, overridden: n.overridden}
+		}
+		mergeObject(n, val, scope)
+	case []interface{}:
+		if !n.isArray {
+			n.recordOverride()
+			*n = node{isArray: true, overridden: n.overridden}
+		}
+		for _, item := range val {
+			if !containsValue(n.items, item) {
+				n.items = append(n.items, models.SettingValue{Source: scope, Value: item})
+			}
+		}
+	default:
+		n.recordOverride()
+		overridden := n.overridden
+		*n = node{source: scope, value: val, overridden: overridden}
+	}
+}
+
+// recordOverride remembers the node's current value before it is replaced.
+func (n *node) recordOverride() {
+	switch {
+	case n.source != "":
+		n.overridden = append(n.overridden, models.SettingValue{Source: n.source, Value: n.value})
+	case n.isArray:
+		n.overridden = append(n.overridden, n.items...)
+	case n.children != nil:
+		// An object replaced by a scalar or array: keep each of its leaves.
+		var leaves []models.SettingProvenance
+		materialize(n, "", &leaves)
+		for _, leaf := range leaves {
+			if leaf.Source != "" {
+				n.overridden = append(n.overridden, models.SettingValue{Source: leaf.Source, Value: leaf.Value})
+			}
+			n.overridden = append(n.overridden, leaf.Items...)
+		}
+	}
+}
+
+func containsValue(items []models.SettingValue, v interface{}) bool {
+	want, _ := json.Marshal(v)
+	for _, it := range items {
+		got, _ := json.Marshal(it.Value)
+		if bytes.Equal(got, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// materialize builds the plain merged value and appends provenance for
+// every leaf (scalar or array) below pointer.
+func materialize(n *node, pointer string, prov *[]models.SettingProvenance) interface{} {
+	switch {
+	case n.children != nil:
+		obj := make(map[string]interface{}, len(n.children))
+		keys := make([]string, 0, len(n.children))
+		for k := range n.children {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			obj[k] = materialize(n.children[k], pointer+"/"+escapePointer(k), prov)
+		}
+		if len(n.overridden) > 0 {
+			*prov = append(*prov, models.SettingProvenance{Pointer: pointer, Value: obj, Overridden: n.overridden})
+		}
+		return obj
+	case n.isArray:
+		arr := make([]interface{}, len(n.items))
+		for i, it := range n.items {
+			arr[i] = it.Value
+		}
+		*prov = append(*prov, models.SettingProvenance{
+			Pointer:    pointer,
+			Value:      arr,
+			Items:      n.items,
+			Overridden: n.overridden,
+		})
+		return arr
+	default:
+		*prov = append(*prov, models.SettingProvenance{
+			Pointer:    pointer,
+			Value:      n.value,
+			Source:     n.source,
+			Overridden: n.overridden,
+		})
+		return n.value
+	}
+}
+
+// escapePointer escapes a key for use in a JSON pointer (RFC 6901).
+func escapePointer(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
@@ -0,0 +1,125 @@
+package store
+
+import (
+	"sync"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+)
+
+// Store holds the current scan result and guards it against concurrent access.
+//
+// Readers receive immutable snapshots: every mutation builds a fresh
+// ScanResult (and Files slice) instead of editing the published one, so a
+// snapshot handed out earlier is never modified underneath its holder.
+type Store struct {
+	mu     sync.RWMutex
+	result *models.ScanResult
+}
+
+// New creates an empty store.
+func New() *Store {
+	return &Store{result: &models.ScanResult{}}
+}
+
+// Snapshot returns the current scan result. Callers must treat it as read-only.
+func (s *Store) Snapshot() *models.ScanResult {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.result
+}
+
+// Replace publishes a new scan result, typically after a full rescan.
+func (s *Store) Replace(result *models.ScanResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.result = result
+}
+
+// Find returns a copy of the entry with the given ID.
+func (s *Store) Find(id string) (models.FileEntry, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, f := range s.result.Files {
+		if f.ID == id {
+			return f, true
+		}
+	}
+	return models.FileEntry{}, false
+}
+
+// Update applies fn to a copy of the entry with the given ID and publishes the
+// result. It returns the updated entry, or false if the ID is unknown.
+func (s *Store) Update(id string, fn func(*models.FileEntry)) (models.FileEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, f := range s.result.Files {
+		if f.ID != id {
+			continue
+		}
+		files := make([]models.FileEntry, len(s.result.Files))
+		copy(files, s.result.Files)
+		fn(&files[i])
+		s.publish(files)
+		return files[i], true
+	}
+	return models.FileEntry{}, false
+}
+
+// Upsert inserts entries, replacing any existing entries with the same ID.
+func (s *Store) Upsert(entries ...models.FileEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	byID := make(map[string]models.FileEntry, len(entries))
+	for _, e := range entries {
+		byID[e.ID] = e
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	files := make([]models.FileEntry, 0, len(s.result.Files)+len(entries))
+	for _, f := range s.result.Files {
+		if e, ok := byID[f.ID]; ok {
+			files = append(files, e)
+			delete(byID, f.ID)
+			continue
+		}
+		files = append(files, f)
+	}
+	for _, e := range entries {
+		if last, pending := byID[e.ID]; pending {
+			files = append(files, last) // the last entry given for an ID wins
+			delete(byID, e.ID)
+		}
+	}
+	s.publish(files)
+}
+
+// Remove drops the entries with the given IDs and returns how many were removed.
+func (s *Store) Remove(ids ...string) int {
+	drop := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		drop[id] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	files := make([]models.FileEntry, 0, len(s.result.Files))
+	for _, f := range s.result.Files {
+		if !drop[f.ID] {
+			files = append(files, f)
+		}
+	}
+	removed := len(s.result.Files) - len(files)
+	if removed > 0 {
+		s.publish(files)
+	}
+	return removed
+}
+
+// publish swaps in a new result sharing the current metadata. Must hold mu.
+func (s *Store) publish(files []models.FileEntry) {
+	next := *s.result
+	next.Files = files
+	s.result = &next
+}
@@ -0,0 +1,130 @@
+package store
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+)
+
+func entries(ids ...string) []models.FileEntry {
+	files := make([]models.FileEntry, len(ids))
+	for i, id := range ids {
+		files[i] = models.FileEntry{ID: id, Name: id + ".md", Size: int64(i)}
+	}
+	return files
+}
+
+func ids(files []models.FileEntry) []string {
+	out := []string{}
+	for _, f := range files {
+		out = append(out, f.ID)
+	}
+	return out
+}
+
+// seeded returns a store holding a, b and c, and a deep copy of its snapshot.
+func seeded() (*Store, *models.ScanResult, []models.FileEntry) {
+	s := New()
+	s.Replace(&models.ScanResult{Files: entries("a", "b", "c")})
+	snap := s.Snapshot()
+	return s, snap, append([]models.FileEntry(nil), snap.Files...)
+}
+
+// checkSnapshot fails if a snapshot taken before a mutation has changed.
+func checkSnapshot(t *testing.T, snap *models.ScanResult, want []models.FileEntry) {
+	t.Helper()
+	if !reflect.DeepEqual(snap.Files, want) {
+		t.Errorf("earlier snapshot changed: got %v, want %v", snap.Files, want)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     string
+		wantOK bool
+		want   []string
+	}{
+		{"first", "a", true, []string{"a", "b", "c"}},
+		{"last", "c", true, []string{"a", "b", "c"}},
+		{"unknown", "x", false, []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, snap, before := seeded()
+			got, ok := s.Update(tt.id, func(e *models.FileEntry) { e.Size = 99 })
+			if ok != tt.wantOK {
+				t.Fatalf("Update(%q) ok = %v, want %v", tt.id, ok, tt.wantOK)
+			}
+			if ok && got.Size != 99 {
+				t.Errorf("Update(%q) returned size %d, want 99", tt.id, got.Size)
+			}
+			if f, _ := s.Find(tt.id); ok && f.Size != 99 {
+				t.Errorf("Find(%q) after Update: size %d, want 99", tt.id, f.Size)
+			}
+			if got := ids(s.Snapshot().Files); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("files = %v, want %v", got, tt.want)
+			}
+			checkSnapshot(t, snap, before)
+		})
+	}
+}
+
+func TestRemove(t *testing.T) {
+	tests := []struct {
+		name   string
+		remove []string
+		wantN  int
+		want   []string
+	}{
+		{"one", []string{"b"}, 1, []string{"a", "c"}},
+		{"all", []string{"a", "b", "c"}, 3, []string{}},
+		{"unknown", []string{"x"}, 0, []string{"a", "b", "c"}},
+		{"mixed", []string{"x", "a", "a"}, 1, []string{"b", "c"}},
+		{"none", nil, 0, []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, snap, before := seeded()
+			if n := s.Remove(tt.remove...); n != tt.wantN {
+				t.Errorf("Remove(%v) = %d, want %d", tt.remove, n, tt.wantN)
+			}
+			if got := ids(s.Snapshot().Files); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("files = %v, want %v", got, tt.want)
+			}
+			checkSnapshot(t, snap, before)
+		})
+	}
+}
+
+func TestUpsert(t *testing.T) {
+	tests := []struct {
+		name    string
+		upsert  []models.FileEntry
+		want    []string
+		changed string // ID whose entry must now be named "new.md"
+	}{
+		{"insert", []models.FileEntry{{ID: "d", Name: "new.md"}}, []string{"a", "b", "c", "d"}, "d"},
+		{"replace", []models.FileEntry{{ID: "b", Name: "new.md"}}, []string{"a", "b", "c"}, "b"},
+		{"both", []models.FileEntry{{ID: "e", Name: "e.md"}, {ID: "a", Name: "new.md"}}, []string{"a", "b", "c", "e"}, "a"},
+		{"repeated new", []models.FileEntry{{ID: "d", Name: "old.md"}, {ID: "d", Name: "new.md"}}, []string{"a", "b", "c", "d"}, "d"},
+		{"repeated existing", []models.FileEntry{{ID: "c", Name: "old.md"}, {ID: "c", Name: "new.md"}}, []string{"a", "b", "c"}, "c"},
+		{"none", nil, []string{"a", "b", "c"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, snap, before := seeded()
+			s.Upsert(tt.upsert...)
+			if got := ids(s.Snapshot().Files); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("files = %v, want %v", got, tt.want)
+			}
+			if tt.changed != "" {
+				if f, _ := s.Find(tt.changed); f.Name != "new.md" {
+					t.Errorf("entry %q has name %q, want %q", tt.changed, f.Name, "new.md")
+				}
+			}
+			checkSnapshot(t, snap, before)
+		})
+	}
+}
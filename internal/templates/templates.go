@@ -0,0 +1,88 @@
+package templates
+
+import (
+	"strings"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+)
+
+// Seed content for newly created files. "{{name}}" is replaced with the
+// file's short name and "{{title}}" with a human-readable form of it.
+const (
+	agentTemplate = `---
+name: {{name}}
+description: Describe when Claude should delegate to this agent.
+tools: Read, Grep, Glob
+---
+
+You are {{title}}. Describe the agent's role, responsibilities and constraints here.
+`
+
+	skillTemplate = `---
+name: {{name}}
+description: Describe what this skill does and when Claude should use it.
+---
+
+# {{title}}
+
+## Instructions
+
+Step-by-step guidance for Claude goes here.
+`
+
+	commandTemplate = `---
+description: Describe what /{{name}} does.
+argument-hint: [arguments]
+---
+
+Instructions for the /{{name}} command. Use $ARGUMENTS to refer to the arguments passed in.
+`
+
+	projectTemplate = `# {{title}}
+
+## Overview
+
+Describe the project for Claude: purpose, architecture and key directories.
+
+## Commands
+
+- Build:
+- Test:
+- Lint:
+
+## Conventions
+
+Coding style, naming and other rules Claude should follow.
+`
+)
+
+// For returns the seed content for a new file of the given category.
+func For(cat models.Category, name string) string {
+	var tmpl string
+	switch cat {
+	case models.CategoryAgents:
+		tmpl = agentTemplate
+	case models.CategorySkills:
+		tmpl = skillTemplate
+	case models.CategoryCommands:
+		// Namespaced commands ("frontend/build") are invoked as /frontend:build.
+		name = strings.ReplaceAll(name, "/", ":")
+		tmpl = commandTemplate
+	case models.CategoryProject:
+		tmpl = projectTemplate
+	default:
+		return ""
+	}
+	return strings.NewReplacer("{{name}}", name, "{{title}}", title(name)).Replace(tmpl)
+}
+
+// title turns "code-reviewer" into "Code Reviewer".
+func title(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return r == '-' || r == '_' || r == '/' || r == ':' || r == ' '
+	})
+	for i, w := range words {
+		words[i] = strings.ToUpper(w[:1]) + w[1:]
+	}
+	return strings.Join(words, " ")
+}
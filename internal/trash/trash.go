@@ -0,0 +1,243 @@
+package trash
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/fsutil"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+)
+
+// Layout of a trashed item inside the bin directory:
+//
+//	<dir>/<id>/meta.json   item metadata (models.TrashItem)
+//	<dir>/<id>/data        the file or directory itself
+//
+// The payload is stored under a neutral name so trashed CLAUDE.md files are
+// never picked up by the scanner.
+const (
+	metaFile = "meta.json"
+	dataName = "data"
+)
+
+// ErrNotFound is returned for unknown trash item IDs.
+var ErrNotFound = errors.New("trash item not found")
+
+// ErrTargetExists is returned when restoring over an existing path.
+var ErrTargetExists = errors.New("a file already exists at the original location")
+
+// Bin is a ClaudeShelf-managed trash directory.
+type Bin struct {
+	dir       string
+	retention time.Duration // zero keeps items forever
+	mu        sync.Mutex
+}
+
+// New creates a bin rooted at dir. Items older than retention are removed by
+// PurgeExpired; a zero retention disables automatic purging.
+func New(dir string, retention time.Duration) *Bin {
+	return &Bin{dir: dir, retention: retention}
+}
+
+// Retention returns the configured retention period.
+func (b *Bin) Retention() time.Duration {
+	return b.retention
+}
+
+// Trash moves path into the bin and records where it came from. The entry,
+// if non-nil, supplies display metadata for the listing.
+func (b *Bin) Trash(path string, entry *models.FileEntry, reason string) (models.TrashItem, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return models.TrashItem{}, err
+	}
+
+	item := models.TrashItem{
+		ID:           newID(),
+		OriginalPath: path,
+		RelPath:      path,
+		Name:         filepath.Base(path),
+		IsDir:        info.IsDir(),
+		Size:         treeSize(path),
+		DeletedAt:    time.Now(),
+		Reason:       reason,
+	}
+	if entry != nil {
+		item.RelPath = entry.RelPath
+		item.DisplayName = entry.DisplayName
+		item.Category = entry.Category
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	itemDir := filepath.Join(b.dir, item.ID)
+	if err := os.MkdirAll(itemDir, 0700); err != nil {
+		return models.TrashItem{}, err
+	}
+	if err := writeMeta(itemDir, item); err != nil {
+		os.RemoveAll(itemDir)
+		return models.TrashItem{}, err
+	}
+	if err := fsutil.Move(path, filepath.Join(itemDir, dataName)); err != nil {
+		os.RemoveAll(itemDir)
+		return models.TrashItem{}, err
+	}
+	return item, nil
+}
+
+// List returns all trashed items, newest first.
+func (b *Bin) List() ([]models.TrashItem, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.list()
+}
+
+func (b *Bin) list() ([]models.TrashItem, error) {
+	dirs, err := os.ReadDir(b.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var items []models.TrashItem
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		item, err := readMeta(filepath.Join(b.dir, d.Name()))
+		if err != nil {
+			continue // skip damaged entries
+		}
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+	return items, nil
+}
+
+// Restore moves an item back to its original path and removes it from the bin.
+func (b *Bin) Restore(id string) (models.TrashItem, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	itemDir, err := b.itemDir(id)
+	if err != nil {
+		return models.TrashItem{}, err
+	}
+	item, err := readMeta(itemDir)
+	if err != nil {
+		return models.TrashItem{}, err
+	}
+	if err := fsutil.Move(filepath.Join(itemDir, dataName), item.OriginalPath); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return item, ErrTargetExists
+		}
+		return item, err
+	}
+	return item, os.RemoveAll(itemDir)
+}
+
+// Purge permanently deletes a single item.
+func (b *Bin) Purge(id string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	itemDir, err := b.itemDir(id)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(itemDir)
+}
+
+// PurgeOlderThan permanently deletes items trashed before now minus age and
+// returns how many were removed. A zero age purges everything.
+func (b *Bin) PurgeOlderThan(age time.Duration) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	items, err := b.list()
+	if err != nil {
+		return 0, err
+	}
+	cutoff := time.Now().Add(-age)
+	purged := 0
+	for _, item := range items {
+		if item.DeletedAt.After(cutoff) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(b.dir, item.ID)); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// PurgeExpired applies the retention policy.
+func (b *Bin) PurgeExpired() (int, error) {
+	if b.retention <= 0 {
+		return 0, nil
+	}
+	return b.PurgeOlderThan(b.retention)
+}
+
+// itemDir validates id and returns its directory. IDs are generated by the
+// bin, so anything containing path separators is rejected outright.
+func (b *Bin) itemDir(id string) (string, error) {
+	if id == "" || id != filepath.Base(id) || id == "." || id == ".." {
+		return "", ErrNotFound
+	}
+	dir := filepath.Join(b.dir, id)
+	if _, err := os.Stat(filepath.Join(dir, metaFile)); err != nil {
+		return "", ErrNotFound
+	}
+	return dir, nil
+}
+
+func writeMeta(itemDir string, item models.TrashItem) error {
+	data, err := json.MarshalIndent(item, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(filepath.Join(itemDir, metaFile), data, 0600)
+}
+
+func readMeta(itemDir string) (models.TrashItem, error) {
+	var item models.TrashItem
+	data, err := os.ReadFile(filepath.Join(itemDir, metaFile))
+	if err != nil {
+		return item, err
+	}
+	err = json.Unmarshal(data, &item)
+	return item, err
+}
+
+// newID returns a sortable, unique item ID.
+func newID() string {
+	var b [4]byte
+	rand.Read(b[:])
+	return fmt.Sprintf("%d-%x", time.Now().UnixNano(), b)
+}
+
+// treeSize returns the total size of a file or directory tree.
+func treeSize(path string) int64 {
+	var total int64
+	filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
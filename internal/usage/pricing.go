@@ -0,0 +1,50 @@
+package usage
+
+import (
+	"strings"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+)
+
+// price is a model's list price in USD per million tokens.
+type price struct {
+	input, output, cacheWrite, cacheRead float64
+}
+
+// prices maps model ID fragments to list prices. The first fragment
+// contained in a model ID wins, so more specific entries come first.
+var prices = []struct {
+	fragment string
+	price    price
+}{
+	{"opus-4-5", price{5, 25, 6.25, 0.50}},
+	{"opus-4", price{15, 75, 18.75, 1.50}},
+	{"3-opus", price{15, 75, 18.75, 1.50}},
+	{"sonnet", price{3, 15, 3.75, 0.30}},
+	{"haiku-4", price{1, 5, 1.25, 0.10}},
+	{"3-5-haiku", price{0.80, 4, 1, 0.08}},
+	{"3-haiku", price{0.25, 1.25, 0.30, 0.03}},
+}
+
+// priceOf returns the list price of a model, or false if it is unknown.
+func priceOf(model string) (price, bool) {
+	for _, p := range prices {
+		if strings.Contains(model, p.fragment) {
+			return p.price, true
+		}
+	}
+	return price{}, false
+}
+
+// Cost estimates the USD cost of tokens used with model. It returns false
+// for models without a known price.
+func Cost(model string, t models.TokenUsage) (float64, bool) {
+	p, ok := priceOf(model)
+	if !ok {
+		return 0, false
+	}
+	return (float64(t.Input)*p.input +
+		float64(t.Output)*p.output +
+		float64(t.CacheCreation)*p.cacheWrite +
+		float64(t.CacheRead)*p.cacheRead) / 1e6, true
+}
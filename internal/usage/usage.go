@@ -0,0 +1,159 @@
+// Package usage aggregates token usage from session transcripts into
+// per-session, per-project, per-model and per-day totals with cost estimates.
+package usage
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/models"
+)
+
+// Groups accepted by WriteCSV.
+const (
+	GroupSession = "session"
+	GroupProject = "project"
+	GroupModel   = "model"
+	GroupDay     = "day"
+)
+
+// Report aggregates records dated within [from, to]. Either bound may be
+// empty; dates are compared as YYYY-MM-DD strings.
+func Report(records []models.UsageRecord, from, to string) models.UsageReport {
+	report := models.UsageReport{From: from, To: to, Total: models.UsageRow{Key: "total"}}
+	bySession := make(map[string]*models.UsageRow)
+	byProject := make(map[string]*models.UsageRow)
+	byModel := make(map[string]*models.UsageRow)
+	byDay := make(map[string]*models.UsageRow)
+	unpriced := make(map[string]bool)
+
+	for _, rec := range records {
+		if (from != "" && rec.Date < from) || (to != "" && rec.Date > to) {
+			continue
+		}
+		cost, ok := Cost(rec.Model, rec.TokenUsage)
+		if !ok {
+			unpriced[rec.Model] = true
+		}
+		add(&report.Total, rec, cost)
+		session := row(bySession, rec.SessionID)
+		add(session, rec, cost)
+		session.Project, session.ProjectID = rec.Project, rec.ProjectID
+		project := row(byProject, rec.ProjectID)
+		add(project, rec, cost)
+		project.Project = rec.Project
+		add(row(byModel, rec.Model), rec, cost)
+		add(row(byDay, rec.Date), rec, cost)
+	}
+
+	report.BySession = sortedRows(bySession, false)
+	report.ByProject = sortedRows(byProject, false)
+	report.ByModel = sortedRows(byModel, false)
+	report.ByDay = sortedRows(byDay, true)
+	report.UnpricedModels = make([]string, 0, len(unpriced))
+	for m := range unpriced {
+		report.UnpricedModels = append(report.UnpricedModels, m)
+	}
+	sort.Strings(report.UnpricedModels)
+	return report
+}
+
+// Rows returns the rows of one group of a report, or false for an unknown group.
+func Rows(report models.UsageReport, group string) ([]models.UsageRow, bool) {
+	switch group {
+	case GroupSession:
+		return report.BySession, true
+	case GroupProject:
+		return report.ByProject, true
+	case GroupModel:
+		return report.ByModel, true
+	case GroupDay:
+		return report.ByDay, true
+	}
+	return nil, false
+}
+
+// WriteCSV writes rows as CSV with a header line. The first column is
+// named after the group. Projects are identified by their ProjectID and
+// followed by their name.
+func WriteCSV(w io.Writer, group string, rows []models.UsageRow) error {
+	cw := csv.NewWriter(w)
+	header := []string{group}
+	switch group {
+	case GroupSession:
+		header = append(header, "project", "project_name")
+	case GroupProject:
+		header = append(header, "project_name")
+	}
+	header = append(header, "messages", "input_tokens", "output_tokens",
+		"cache_creation_tokens", "cache_read_tokens", "cost_usd")
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		rec := []string{r.Key}
+		switch group {
+		case GroupSession:
+			rec = append(rec, r.ProjectID, r.Project)
+		case GroupProject:
+			rec = append(rec, r.Project)
+		}
+		rec = append(rec,
+			strconv.Itoa(r.Messages),
+			strconv.FormatInt(r.Input, 10),
+			strconv.FormatInt(r.Output, 10),
+			strconv.FormatInt(r.CacheCreation, 10),
+			strconv.FormatInt(r.CacheRead, 10),
+			fmt.Sprintf("%.4f", r.CostUSD),
+		)
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func row(m map[string]*models.UsageRow, key string) *models.UsageRow {
+	r := m[key]
+	if r == nil {
+		r = &models.UsageRow{Key: key}
+		m[key] = r
+	}
+	return r
+}
+
+func add(r *models.UsageRow, rec models.UsageRecord, cost float64) {
+	r.Messages += rec.Messages
+	r.CostUSD += cost
+	r.TokenUsage.Add(rec.TokenUsage)
+}
+
+// sortedRows orders rows by key when byKey is set (days), otherwise by cost
+// and then total tokens, largest first.
+func sortedRows(m map[string]*models.UsageRow, byKey bool) []models.UsageRow {
+	rows := make([]models.UsageRow, 0, len(m))
+	for _, r := range m {
+		rows = append(rows, *r)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if !byKey {
+			if a.CostUSD != b.CostUSD {
+				return a.CostUSD > b.CostUSD
+			}
+			if ta, tb := total(a.TokenUsage), total(b.TokenUsage); ta != tb {
+				return ta > tb
+			}
+		}
+		return a.Key < b.Key
+	})
+	return rows
+}
+
+func total(t models.TokenUsage) int64 {
+	return t.Input + t.Output + t.CacheCreation + t.CacheRead
+}
@@ -0,0 +1,119 @@
+//go:build linux
+
+package watcher
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/scanner"
+)
+
+// watchMask selects the inotify events that can change a scan result.
+const watchMask = syscall.IN_CREATE | syscall.IN_CLOSE_WRITE | syscall.IN_MODIFY |
+	syscall.IN_ATTRIB | syscall.IN_DELETE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO |
+	syscall.IN_DELETE_SELF | syscall.IN_MOVE_SELF
+
+// inotify watches the scanner's directories with one inotify instance.
+// Directories created later are added as they appear.
+type inotify struct {
+	sc   *scanner.Scanner
+	file *os.File
+	fd   int
+	dirs map[int32]string // watch descriptor → directory
+}
+
+func newNative(sc *scanner.Scanner) (notifier, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("inotify: %w", err)
+	}
+	// A non-blocking descriptor is registered with the runtime poller, so
+	// closing the file interrupts a pending read.
+	in := &inotify{sc: sc, file: os.NewFile(uintptr(fd), "inotify"), fd: fd, dirs: make(map[int32]string)}
+	for _, dir := range sc.WatchDirs() {
+		if err := in.add(dir); errors.Is(err, syscall.ENOSPC) {
+			in.file.Close()
+			return nil, fmt.Errorf("inotify watch limit reached (fs.inotify.max_user_watches): %w", err)
+		}
+	}
+	return in, nil
+}
+
+func (in *inotify) add(dir string) error {
+	wd, err := syscall.InotifyAddWatch(in.fd, dir, watchMask|syscall.IN_ONLYDIR)
+	if err != nil {
+		return err
+	}
+	in.dirs[int32(wd)] = dir
+	return nil
+}
+
+// addTree watches a new directory and any watchable directories below it.
+func (in *inotify) addTree(dir string) {
+	for _, d := range in.sc.WatchDirsUnder(dir) {
+		in.add(d)
+	}
+}
+
+func (in *inotify) run(ctx context.Context, out chan<- string) error {
+	go func() {
+		<-ctx.Done()
+		in.file.Close()
+	}()
+
+	buf := make([]byte, 64<<10)
+	for {
+		n, err := in.file.Read(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("inotify: %w", err)
+		}
+		for off := 0; off+syscall.SizeofInotifyEvent <= n; {
+			wd := int32(binary.NativeEndian.Uint32(buf[off:]))
+			mask := binary.NativeEndian.Uint32(buf[off+4:])
+			nameLen := int(binary.NativeEndian.Uint32(buf[off+12:]))
+			name := strings.TrimRight(string(buf[off+syscall.SizeofInotifyEvent:off+syscall.SizeofInotifyEvent+nameLen]), "\x00")
+			off += syscall.SizeofInotifyEvent + nameLen
+
+			in.handle(ctx, out, wd, mask, name)
+		}
+	}
+}
+
+func (in *inotify) handle(ctx context.Context, out chan<- string, wd int32, mask uint32, name string) {
+	if mask&syscall.IN_Q_OVERFLOW != 0 {
+		send(ctx, out, "")
+		return
+	}
+	dir, ok := in.dirs[wd]
+	if !ok {
+		return
+	}
+	if mask&syscall.IN_IGNORED != 0 {
+		delete(in.dirs, wd) // the directory was removed or unmounted
+		return
+	}
+
+	path := dir
+	if name != "" {
+		path = filepath.Join(dir, name)
+	}
+	switch {
+	case mask&syscall.IN_MOVE_SELF != 0:
+		// The watch would follow the directory to a path we no longer know.
+		syscall.InotifyRmWatch(in.fd, uint32(wd))
+		delete(in.dirs, wd)
+	case mask&syscall.IN_ISDIR != 0 && mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0:
+		in.addTree(path)
+	}
+	send(ctx, out, path)
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package watcher
+
+import (
+	"errors"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/scanner"
+)
+
+func newNative(*scanner.Scanner) (notifier, error) {
+	return nil, errors.New("native file notifications are not supported on this platform")
+}
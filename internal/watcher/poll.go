@@ -0,0 +1,162 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/scanner"
+)
+
+// poller detects changes by periodically stating the watched directories and
+// the files in them. Only a directory whose modification time changed is
+// listed again, to find added entries; files are compared by size and
+// modification time. Unlike a rescan, this neither holds the scanner nor
+// rewrites its cache.
+type poller struct {
+	sc       *scanner.Scanner
+	interval time.Duration
+
+	dirs  map[string]*polledDir // watched directories
+	files map[string]stamp      // files in watched directories
+}
+
+// polledDir is what a poller last saw of a watched directory.
+type polledDir struct {
+	modTime time.Time
+	subdirs map[string]bool // names of subdirectories already considered
+}
+
+type stamp struct {
+	size    int64
+	modTime time.Time
+}
+
+func (p *poller) run(ctx context.Context, out chan<- string) error {
+	p.dirs = make(map[string]*polledDir)
+	p.files = make(map[string]stamp)
+	for _, dir := range p.sc.WatchDirs() {
+		p.add(dir)
+	}
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		for _, path := range p.poll() {
+			send(ctx, out, path)
+		}
+	}
+}
+
+// add starts polling a directory and returns the files found in it.
+func (p *poller) add(dir string) []string {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil
+	}
+	d := &polledDir{modTime: info.ModTime(), subdirs: make(map[string]bool)}
+	p.dirs[dir] = d
+	files, _ := p.list(dir, d)
+	return files
+}
+
+// list reads a watched directory. It stamps and returns the files not seen
+// before, and returns the subdirectories not considered before.
+func (p *poller) list(dir string, d *polledDir) (files, subdirs []string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil
+	}
+	seen := make(map[string]bool, len(d.subdirs))
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		if e.IsDir() {
+			seen[e.Name()] = true
+			if !d.subdirs[e.Name()] {
+				subdirs = append(subdirs, path)
+			}
+			continue
+		}
+		if _, ok := p.files[path]; ok {
+			continue
+		}
+		if info, err := e.Info(); err == nil {
+			p.files[path] = stamp{info.Size(), info.ModTime()}
+			files = append(files, path)
+		}
+	}
+	d.subdirs = seen
+	return files, subdirs
+}
+
+// poll returns the paths that changed since the previous poll.
+func (p *poller) poll() []string {
+	var changed, relist []string
+	for dir, d := range p.dirs {
+		info, err := os.Stat(dir)
+		switch {
+		case err != nil:
+			p.remove(dir)
+			changed = append(changed, dir)
+		case !info.ModTime().Equal(d.modTime):
+			d.modTime = info.ModTime()
+			relist = append(relist, dir)
+		}
+	}
+	for path, st := range p.files {
+		info, err := os.Lstat(path)
+		switch {
+		case err != nil:
+			delete(p.files, path)
+			changed = append(changed, path)
+		case info.Size() != st.size || !info.ModTime().Equal(st.modTime):
+			p.files[path] = stamp{info.Size(), info.ModTime()}
+			changed = append(changed, path)
+		}
+	}
+
+	sort.Strings(relist)
+	for _, dir := range relist {
+		d, ok := p.dirs[dir]
+		if !ok {
+			continue
+		}
+		files, subdirs := p.list(dir, d)
+		changed = append(changed, files...)
+		for _, sub := range subdirs {
+			// A new directory may hold Claude files already, e.g. when it
+			// was moved in; report it so its contents are picked up.
+			for _, w := range p.sc.WatchDirsUnder(sub) {
+				if _, ok := p.dirs[w]; !ok {
+					p.add(w)
+				}
+			}
+			changed = append(changed, sub)
+		}
+	}
+	return changed
+}
+
+// remove stops polling a directory that no longer exists, and everything
+// below it.
+func (p *poller) remove(dir string) {
+	prefix := dir + string(os.PathSeparator)
+	for d := range p.dirs {
+		if d == dir || strings.HasPrefix(d, prefix) {
+			delete(p.dirs, d)
+		}
+	}
+	for f := range p.files {
+		if strings.HasPrefix(f, prefix) {
+			delete(p.files, f)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+// Package watcher reports changes to the files covered by a scanner, using
+// inotify on Linux and polling elsewhere.
+package watcher
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/MojtabaTajik/ClaudeShelf/internal/scanner"
+)
+
+const (
+	// settleDelay collects bursts of events, such as an editor's
+	// write-and-rename, into one batch.
+	settleDelay = 250 * time.Millisecond
+	// pollInterval is the polling period when native notifications are not
+	// available.
+	pollInterval = 10 * time.Second
+)
+
+// Batch is a set of changed paths collected over settleDelay. Paths may be
+// files or directories, and may no longer exist.
+type Batch struct {
+	Paths []string
+	// Resync is set when events were lost and the caller should rescan
+	// everything instead.
+	Resync bool
+}
+
+// notifier is a source of raw change notifications.
+type notifier interface {
+	// run sends changed paths to out until ctx is done. An empty path means
+	// events were lost.
+	run(ctx context.Context, out chan<- string) error
+}
+
+// Run watches the directories of sc until ctx is done, calling fn for each
+// batch of changes. fn is called from a single goroutine.
+func Run(ctx context.Context, sc *scanner.Scanner, fn func(Batch)) error {
+	var n notifier
+	if native, err := newNative(sc); err == nil {
+		n = native
+	} else {
+		log.Printf("File watching falls back to polling every %s: %v", pollInterval, err)
+		n = &poller{sc: sc, interval: pollInterval}
+	}
+
+	out := make(chan string, 256)
+	errc := make(chan error, 1)
+	go func() { errc <- n.run(ctx, out) }()
+
+	pending := make(map[string]bool)
+	resync := false
+	var settle <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return <-errc
+		case err := <-errc:
+			return err
+		case p := <-out:
+			if p == "" {
+				resync = true
+			} else {
+				pending[p] = true
+			}
+			if settle == nil {
+				settle = time.After(settleDelay)
+			}
+		case <-settle:
+			settle = nil
+			b := Batch{Resync: resync}
+			if !resync {
+				for p := range pending {
+					b.Paths = append(b.Paths, p)
+				}
+				sort.Strings(b.Paths)
+			}
+			pending = make(map[string]bool)
+			resync = false
+			fn(b)
+		}
+	}
+}
+
+// send delivers a path unless ctx is done first.
+func send(ctx context.Context, out chan<- string, path string) {
+	select {
+	case out <- path:
+	case <-ctx.Done():
+	}
+}
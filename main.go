@@ -6,32 +6,80 @@ import (
 	"io/fs"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/MojtabaTajik/ClaudeShelf/internal/appdir"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/history"
 	"github.com/MojtabaTajik/ClaudeShelf/internal/scanner"
 	"github.com/MojtabaTajik/ClaudeShelf/internal/server"
+	"github.com/MojtabaTajik/ClaudeShelf/internal/trash"
 	"github.com/MojtabaTajik/ClaudeShelf/web"
 )
 
 func main() {
 	port := flag.Int("port", 8010, "Port to run the web server on")
-	path := flag.String("path", "", "Directory to scan for Claude files (empty = scan common locations)")
+	var paths stringList
+	flag.Var(&paths, "path", "Directory to scan for Claude files, optionally as name=dir to set its workspace; may be a glob (repeatable, default: common locations)")
+	watch := flag.Bool("watch", true, "Watch scanned directories and push changes to the browser")
+	trashDays := flag.Int("trash-days", 30, "Days to keep deleted files in the trash (0 = keep forever)")
+	configPath := flag.String("config", filepath.Join(appdir.Dir(), "config.json"), "Scanner configuration file")
+	var excludes stringList
+	flag.Var(&excludes, "exclude", "Gitignore-style pattern of files or directories to skip (repeatable)")
+	maxDepth := flag.Int("max-depth", scanner.DefaultMaxDepth, "Subdirectory levels searched below project directories (-1 = unlimited)")
+	discover := flag.Bool("discover-repos", false, "Find git repositories at any depth below project directories and scan their CLAUDE.md files")
+	exts := flag.String("ext", "", "Comma-separated extensions accepted inside .claude directories (default: md,json,yaml,yml,txt,toml,log,sh)")
 	flag.Parse()
 
-	// Validate path if provided
-	if *path != "" {
-		info, err := os.Stat(*path)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: path %q does not exist: %v\n", *path, err)
-			os.Exit(1)
-		}
-		if !info.IsDir() {
-			fmt.Fprintf(os.Stderr, "Error: path %q is not a directory\n", *path)
-			os.Exit(1)
+	// Validate paths if provided
+	var roots []scanner.Root
+	for _, arg := range paths {
+		root := scanner.ParseRoot(arg)
+		if strings.ContainsAny(root.Path, "*?[") {
+			if matches, err := filepath.Glob(root.Path); err != nil || len(matches) == 0 {
+				fmt.Fprintf(os.Stderr, "Error: path %q matches no directory\n", root.Path)
+				os.Exit(1)
+			}
+		} else {
+			info, err := os.Stat(root.Path)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: path %q does not exist: %v\n", root.Path, err)
+				os.Exit(1)
+			}
+			if !info.IsDir() {
+				fmt.Fprintf(os.Stderr, "Error: path %q is not a directory\n", root.Path)
+				os.Exit(1)
+			}
 		}
+		roots = append(roots, root)
 	}
 
-	// Create scanner
-	sc := scanner.New(*path)
+	// Scanner configuration, with flags taking precedence over the file
+	cfg, err := scanner.LoadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	cfg.Excludes = append(cfg.Excludes, excludes...)
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "max-depth":
+			cfg.MaxDepth = maxDepth
+		case "ext":
+			cfg.Extensions = strings.Split(*exts, ",")
+		case "discover-repos":
+			cfg.DiscoverRepos = *discover
+		}
+	})
+
+	// Create scanner; rescans skip directories unchanged since the last scan
+	sc, err := scanner.New(roots, cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid scanner configuration: %v\n", err)
+		os.Exit(1)
+	}
+	sc.UseCache(filepath.Join(appdir.CacheDir(), "scan-cache.json"))
 
 	// Prepare embedded static filesystem - strip the "static/" prefix
 	staticFS, err := fs.Sub(web.StaticFS, "static")
@@ -39,8 +87,19 @@ func main() {
 		log.Fatalf("Failed to load embedded files: %v", err)
 	}
 
+	// Deleted files go to a trash bin under the ClaudeShelf data directory
+	bin := trash.New(filepath.Join(appdir.Dir(), "trash"), time.Duration(*trashDays)*24*time.Hour)
+
+	// Prior versions of saved files are kept in a content-addressed history store
+	hist := history.New(filepath.Join(appdir.Dir(), "history"))
+
 	// Create and start server
-	srv := server.New(*port, sc, staticFS)
+	srv := server.New(*port, sc, staticFS, server.Options{
+		Trash:        bin,
+		History:      hist,
+		CleanupRules: filepath.Join(appdir.Dir(), "cleanup-rules.json"),
+		Watch:        *watch,
+	})
 
 	fmt.Println("  _____ _                 _       _____ _          _  __")
 	fmt.Println(" / ____| |               | |     / ____| |        | |/ _|")
@@ -50,8 +109,8 @@ func main() {
 	fmt.Println(" \\_____|_|\\__,_|\\__,_|\\__,_|\\___|_____/|_| |_|\\___|_|_|")
 	fmt.Println()
 
-	if *path != "" {
-		fmt.Printf("Scanning: %s\n", *path)
+	if len(paths) > 0 {
+		fmt.Printf("Scanning: %s\n", strings.Join(paths, ", "))
 	} else {
 		fmt.Println("Scanning: common Claude locations")
 	}
@@ -60,3 +119,13 @@ func main() {
 		log.Fatalf("Server error: %v", err)
 	}
 }
+
+// stringList is a flag that may be given more than once.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
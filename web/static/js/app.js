@@ -7,15 +7,20 @@
     files: [],
     categories: [],
     activeCategory: '',
+    activeWorkspace: '',
     activeFileId: null,
     activeFile: null,
     originalContent: '',
+    etag: '',
     searchQuery: '',
+    contentMatchIds: new Set(),
   };
 
   // ===== DOM Refs =====
   const $ = (sel) => document.querySelector(sel);
   const categoryNav = $('#category-nav');
+  const workspacePicker = $('#workspace-picker');
+  const workspaceSelect = $('#workspace-select');
   const fileList = $('#file-list');
   const searchInput = $('#search-input');
   const rescanBtn = $('#rescan-btn');
@@ -50,6 +55,7 @@
     plans: 'Plans',
     skills: 'Skills',
     agents: 'Agents',
+    commands: 'Commands',
     debug: 'Debug',
     project: 'Project',
     other: 'Other',
@@ -63,6 +69,7 @@
     plans: '<svg viewBox="0 0 24 24" width="16" height="16" fill="none" stroke="currentColor" stroke-width="2"><polygon points="1 6 1 22 8 18 16 22 23 18 23 2 16 6 8 2 1 6"/><line x1="8" y1="2" x2="8" y2="18"/><line x1="16" y1="6" x2="16" y2="22"/></svg>',
     skills: '<svg viewBox="0 0 24 24" width="16" height="16" fill="none" stroke="currentColor" stroke-width="2"><polygon points="12 2 15.09 8.26 22 9.27 17 14.14 18.18 21.02 12 17.77 5.82 21.02 7 14.14 2 9.27 8.91 8.26 12 2"/></svg>',
     agents: '<svg viewBox="0 0 24 24" width="16" height="16" fill="none" stroke="currentColor" stroke-width="2"><rect x="3" y="11" width="18" height="10" rx="2"/><circle cx="12" cy="5" r="3"/><line x1="8" y1="16" x2="8" y2="16.01"/><line x1="16" y1="16" x2="16" y2="16.01"/><path d="M9 20h6"/></svg>',
+    commands: '<svg viewBox="0 0 24 24" width="16" height="16" fill="none" stroke="currentColor" stroke-width="2"><polyline points="4 17 10 11 4 5"/><line x1="12" y1="19" x2="20" y2="19"/></svg>',
     debug: '<svg viewBox="0 0 24 24" width="16" height="16" fill="none" stroke="currentColor" stroke-width="2"><path d="M8 2l1.88 1.88M14.12 3.88L16 2M9 7.13v-1a3.003 3.003 0 1 1 6 0v1"/><path d="M12 20c-3.3 0-6-2.7-6-6v-3a4 4 0 0 1 4-4h4a4 4 0 0 1 4 4v3c0 3.3-2.7 6-6 6"/><path d="M2 11h3M19 11h3M2 15h3M19 15h3M10 20.9a15 15 0 0 0 4 0"/></svg>',
     project: '<svg viewBox="0 0 24 24" width="16" height="16" fill="none" stroke="currentColor" stroke-width="2"><path d="M22 19a2 2 0 0 1-2 2H4a2 2 0 0 1-2-2V5a2 2 0 0 1 2-2h5l2 3h9a2 2 0 0 1 2 2z"/></svg>',
     other: '<svg viewBox="0 0 24 24" width="16" height="16" fill="none" stroke="currentColor" stroke-width="2"><path d="M14 2H6a2 2 0 0 0-2 2v16a2 2 0 0 0 2 2h12a2 2 0 0 0 2-2V8z"/><polyline points="14 2 14 8 20 8"/></svg>',
@@ -86,23 +93,37 @@
     return api('/api/files/' + id);
   }
 
-  async function saveFile(id, content) {
-    return api('/api/files/' + id, {
+  // Saves with If-Match. Resolves to { conflict } instead of throwing when
+  // the file changed on disk since it was loaded (HTTP 409), and to
+  // { invalid } when JSON validation rejected the content (HTTP 422).
+  async function saveFile(id, content, base, etag, force) {
+    const res = await fetch('/api/files/' + id, {
       method: 'PUT',
-      headers: { 'Content-Type': 'application/json' },
-      body: JSON.stringify({ content }),
+      headers: { 'Content-Type': 'application/json', 'If-Match': etag },
+      body: JSON.stringify({ content, base, force: !!force }),
     });
+    if (res.status === 409) {
+      return { conflict: await res.json() };
+    }
+    if (res.status === 422) {
+      return { invalid: await res.json() };
+    }
+    if (!res.ok) {
+      const text = await res.text();
+      throw new Error(text || res.statusText);
+    }
+    return res.json();
   }
 
   async function deleteFileApi(id) {
     return api('/api/files/' + id, { method: 'DELETE' });
   }
 
-  async function bulkDeleteApi(ids) {
+  async function bulkDeleteApi(ids, reason) {
     return api('/api/files/bulk-delete', {
       method: 'POST',
       headers: { 'Content-Type': 'application/json' },
-      body: JSON.stringify({ ids }),
+      body: JSON.stringify({ ids, reason }),
     });
   }
 
@@ -118,6 +139,18 @@
     return api('/api/cleanup');
   }
 
+  async function cleanupOrphansApi(dirs) {
+    return api('/api/cleanup/orphans', {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ dirs }),
+    });
+  }
+
+  async function searchContent(q) {
+    return api('/api/search?q=' + encodeURIComponent(q));
+  }
+
   // ===== Tag HTML Builder =====
   function buildTagsHtml(file) {
     let html = '';
@@ -131,6 +164,10 @@
     if (file.projectName) {
       html += '<span class="tag tag-project-name">' + escapeHtml(file.projectName) + '</span>';
     }
+    // Personal files kept out of version control
+    if (file.local) {
+      html += '<span class="tag tag-local" title="Personal file, not meant to be committed">Local</span>';
+    }
     // Category tag
     if (file.category && categoryLabels[file.category]) {
       html += '<span class="tag tag-category">' + categoryLabels[file.category] + '</span>';
@@ -147,19 +184,33 @@
   // Used so category counts reflect search results.
   function getSearchFilteredFiles() {
     let files = state.files;
+    if (state.activeWorkspace) {
+      files = files.filter(f => f.workspace === state.activeWorkspace);
+    }
     if (state.searchQuery) {
       const q = state.searchQuery.toLowerCase();
       files = files.filter(f =>
         f.name.toLowerCase().includes(q) ||
         f.relPath.toLowerCase().includes(q) ||
         (f.displayName && f.displayName.toLowerCase().includes(q)) ||
-        (f.projectName && f.projectName.toLowerCase().includes(q))
+        (f.projectName && f.projectName.toLowerCase().includes(q)) ||
+        state.contentMatchIds.has(f.id)
       );
     }
     return files;
   }
 
   // ===== Rendering =====
+  // The workspace picker only shows when files come from more than one workspace.
+  function renderWorkspaces() {
+    const names = [...new Set(state.files.map(f => f.workspace).filter(Boolean))].sort();
+    if (!names.includes(state.activeWorkspace)) state.activeWorkspace = '';
+    workspacePicker.style.display = names.length > 1 ? '' : 'none';
+    workspaceSelect.innerHTML = '<option value="">All workspaces</option>';
+    names.forEach((name) => workspaceSelect.appendChild(new Option(name, name)));
+    workspaceSelect.value = state.activeWorkspace;
+  }
+
   function renderCategories() {
     const allBtn = categoryNav.querySelector('[data-category=""]');
     categoryNav.innerHTML = '';
@@ -242,6 +293,9 @@
 
   function getFilteredFiles() {
     let files = state.files;
+    if (state.activeWorkspace) {
+      files = files.filter(f => f.workspace === state.activeWorkspace);
+    }
     if (state.activeCategory) {
       files = files.filter(f => f.category === state.activeCategory);
     }
@@ -251,13 +305,15 @@
         f.name.toLowerCase().includes(q) ||
         f.relPath.toLowerCase().includes(q) ||
         (f.displayName && f.displayName.toLowerCase().includes(q)) ||
-        (f.projectName && f.projectName.toLowerCase().includes(q))
+        (f.projectName && f.projectName.toLowerCase().includes(q)) ||
+        state.contentMatchIds.has(f.id)
       );
     }
     return files;
   }
 
   function updateView() {
+    renderWorkspaces();
     const filtered = getFilteredFiles();
     renderCategories();
     renderFileList(filtered);
@@ -299,6 +355,7 @@
       editorTextarea.value = file.content;
       editorTextarea.readOnly = file.readOnly;
       state.originalContent = file.content;
+      state.etag = file.etag;
 
       saveBtn.style.display = file.readOnly ? 'none' : 'inline-flex';
       saveBtn.disabled = true;
@@ -312,14 +369,23 @@
     }
   }
 
-  async function handleSave() {
+  async function handleSave(force) {
     if (!state.activeFileId) return;
     saveBtn.disabled = true;
     editorStatus.textContent = 'Saving...';
     editorStatus.className = 'editor-status';
 
     try {
-      await saveFile(state.activeFileId, editorTextarea.value);
+      const result = await saveFile(state.activeFileId, editorTextarea.value, state.originalContent, state.etag, force === true);
+      if (result.conflict) {
+        handleSaveConflict(result.conflict);
+        return;
+      }
+      if (result.invalid) {
+        handleValidationFailure(result.invalid);
+        return;
+      }
+      state.etag = result.etag;
       state.originalContent = editorTextarea.value;
       editorStatus.textContent = 'Saved';
       editorStatus.className = 'editor-status saved';
@@ -333,6 +399,52 @@
     }
   }
 
+  // The file changed on disk while open: rebase the editor onto the disk
+  // version with the merged result so the user can review and save again.
+  function handleSaveConflict(conflict) {
+    const merge = conflict.merge;
+    state.etag = conflict.etag;
+    state.originalContent = conflict.current;
+    editorTextarea.value = merge ? merge.merged : conflict.yours;
+    saveBtn.disabled = false;
+    if (merge && merge.conflicts === 0) {
+      editorStatus.textContent = 'Merged with changes on disk';
+      editorStatus.className = 'editor-status modified';
+      toast('File changed on disk; changes merged cleanly. Review and save again.', 'info');
+    } else {
+      editorStatus.textContent = 'Conflict';
+      editorStatus.className = 'editor-status error';
+      toast('File changed on disk. Resolve the marked conflicts and save again.', 'error');
+    }
+  }
+
+  // The content failed JSON validation: select the first problem in the
+  // editor and offer to save anyway.
+  function handleValidationFailure(failure) {
+    const errors = failure.errors || [];
+    const first = errors[0];
+    saveBtn.disabled = false;
+    editorStatus.textContent = errors.length === 1 ? '1 problem' : errors.length + ' problems';
+    editorStatus.className = 'editor-status error';
+    if (!first) return;
+
+    const lines = editorTextarea.value.split('\n');
+    let offset = 0;
+    for (let i = 0; i < first.line - 1 && i < lines.length; i++) {
+      offset += lines[i].length + 1;
+    }
+    offset += first.column - 1;
+    editorTextarea.focus();
+    editorTextarea.setSelectionRange(offset, offset + 1);
+
+    const where = first.pointer ? first.pointer + ' ' : '';
+    const summary = errors.slice(0, 5).map(e => `Line ${e.line}, col ${e.column}: ${e.pointer ? e.pointer + ' ' : ''}${e.message}`).join('\n');
+    toast(`Line ${first.line}: ${where}${first.message}`, 'error');
+    if (confirm(`This file has problems that may break Claude Code:\n\n${summary}\n\nSave anyway?`)) {
+      handleSave(true);
+    }
+  }
+
   // ===== Delete Single File =====
   async function handleDeleteCurrent() {
     if (!state.activeFile) return;
@@ -340,7 +452,7 @@
 
     showConfirmModal(
       'Delete File',
-      'Are you sure you want to delete this file?',
+      'Move this file to the trash? It can be restored later.',
       [file],
       async () => {
         try {
@@ -364,7 +476,7 @@
   async function handleDeleteInline(file) {
     showConfirmModal(
       'Delete File',
-      'Are you sure you want to delete this file?',
+      'Move this file to the trash? It can be restored later.',
       [file],
       async () => {
         try {
@@ -398,7 +510,7 @@
 
     showConfirmModal(
       'Delete All Visible Files',
-      'This will permanently delete <strong>' + deletable.length + ' file' + (deletable.length !== 1 ? 's' : '') + '</strong> matching your current filter.',
+      'This will move <strong>' + deletable.length + ' file' + (deletable.length !== 1 ? 's' : '') + '</strong> matching your current filter to the trash.',
       deletable,
       async () => {
         try {
@@ -500,15 +612,23 @@
   const reasonLabels = {
     empty_file: 'Empty Files',
     empty_content: 'Empty Content',
+    duplicate: 'Duplicates',
     stale: 'Stale Files',
+    orphaned_project: 'Orphaned Projects',
   };
 
   const reasonDescriptions = {
     empty_file: 'Files with 0 bytes',
     empty_content: 'Files containing only [], {}, null, or whitespace',
-    stale: 'Files not modified in 30+ days',
+    duplicate: 'Exact copies of another file, which is kept',
+    stale: 'Files not modified recently',
+    orphaned_project: 'Memory and transcripts of projects that no longer exist on disk',
   };
 
+  // Orphaned project directories are listed alongside files; their
+  // selection keys carry this prefix so they can be told apart on delete.
+  const orphanKeyPrefix = 'orphan:';
+
   cleanupBtn.addEventListener('click', openCleanup);
   cleanupClose.addEventListener('click', closeCleanup);
   cleanupCancel.addEventListener('click', closeCleanup);
@@ -526,7 +646,17 @@
 
     try {
       const result = await fetchCleanup();
-      cleanupState.items = result.items || [];
+      result.items = (result.items || []).concat((result.orphans || []).map(o => ({
+        id: orphanKeyPrefix + o.dir,
+        name: o.dir,
+        displayName: o.name,
+        relPath: o.path,
+        size: o.size,
+        reason: o.reason,
+        reasonLabel: o.fileCount + ' file' + (o.fileCount !== 1 ? 's' : '') + ', ' +
+          o.sessionCount + ' session' + (o.sessionCount !== 1 ? 's' : '') + ' (' + formatSize(o.size) + ')',
+      })));
+      cleanupState.items = result.items;
       renderCleanupResults(result);
     } catch (err) {
       toast('Cleanup analysis failed: ' + err.message, 'error');
@@ -554,7 +684,7 @@
 
     cleanupEmpty.style.display = 'none';
     cleanupSummary.innerHTML =
-      '<strong>' + result.totalCount + '</strong> file' + (result.totalCount !== 1 ? 's' : '') +
+      '<strong>' + result.totalCount + '</strong> item' + (result.totalCount !== 1 ? 's' : '') +
       ' suggested for cleanup (' + formatSize(result.totalSize) + ' total)';
 
     // Group items by reason
@@ -565,7 +695,9 @@
     });
 
     // Build table
-    const order = ['empty_file', 'empty_content', 'stale'];
+    // Built-in reasons first, then any custom reasons from cleanup rules
+    const known = ['orphaned_project', 'empty_file', 'empty_content', 'duplicate', 'stale'];
+    const order = known.concat(Object.keys(groups).filter(r => !known.includes(r)).sort());
     let tableHtml = '<table class="cleanup-table">' +
       '<thead><tr>' +
         '<th><input type="checkbox" id="cleanup-select-all" checked></th>' +
@@ -581,7 +713,7 @@
       tableHtml += '<tr class="cleanup-group-row">' +
         '<td><input type="checkbox" class="group-checkbox" data-reason="' + reason + '" checked></td>' +
         '<td colspan="2"><span class="group-label">' +
-          (reasonLabels[reason] || reason) +
+          (reasonLabels[reason] || escapeHtml(reason)) +
           ' <span class="badge">' + items.length + '</span>' +
         '</span><span class="group-desc">' + (reasonDescriptions[reason] || '') + '</span></td>' +
       '</tr>';
@@ -593,7 +725,8 @@
           '<td><input type="checkbox" class="item-checkbox" data-id="' + item.id + '" checked></td>' +
           '<td><span class="cleanup-cell-name">' + escapeHtml(item.displayName || item.name) + '</span>' +
             '<span class="cleanup-cell-tags">' + tags + '</span></td>' +
-          '<td><span class="cleanup-reason reason-' + item.reason + '">' + escapeHtml(item.reasonLabel) + '</span></td>' +
+          '<td><span class="cleanup-reason reason-' + escapeHtml(item.reason) + '"' +
+            (item.rule ? ' title="Rule: ' + escapeHtml(item.rule) + '"' : '') + '>' + escapeHtml(item.reasonLabel) + '</span></td>' +
         '</tr>';
       });
     });
@@ -668,22 +801,33 @@
     const size = cleanupState.items
       .filter(item => cleanupState.selected.has(item.id))
       .reduce((sum, item) => sum + (item.size || 0), 0);
-    cleanupSelectedInfo.textContent = count + ' file' + (count !== 1 ? 's' : '') + ' selected (' + formatSize(size) + ')';
+    cleanupSelectedInfo.textContent = count + ' item' + (count !== 1 ? 's' : '') + ' selected (' + formatSize(size) + ')';
     cleanupDelete.disabled = count === 0;
   }
 
   cleanupDelete.addEventListener('click', async () => {
-    const ids = Array.from(cleanupState.selected);
-    if (ids.length === 0) return;
+    const selected = Array.from(cleanupState.selected);
+    if (selected.length === 0) return;
+    const ids = selected.filter(k => !k.startsWith(orphanKeyPrefix));
+    const dirs = selected.filter(k => k.startsWith(orphanKeyPrefix)).map(k => k.slice(orphanKeyPrefix.length));
 
     cleanupDelete.disabled = true;
     cleanupDelete.innerHTML = '<span class="spinner"></span> Deleting...';
 
     try {
-      const result = await bulkDeleteApi(ids);
-      toast('Cleaned up ' + result.deleted + ' file(s)', 'success');
-      if (result.errors && result.errors.length > 0) {
-        toast(result.errors.length + ' file(s) failed to delete', 'error');
+      if (ids.length > 0) {
+        const result = await bulkDeleteApi(ids, 'cleanup');
+        toast('Cleaned up ' + result.deleted + ' file(s)', 'success');
+        if (result.errors && result.errors.length > 0) {
+          toast(result.errors.length + ' file(s) failed to delete', 'error');
+        }
+      }
+      if (dirs.length > 0) {
+        const result = await cleanupOrphansApi(dirs);
+        toast('Moved ' + result.deleted + ' orphaned project(s) to the trash (' + formatSize(result.freed) + ')', 'success');
+        if (result.errors && result.errors.length > 0) {
+          toast(result.errors.length + ' project(s) failed to delete', 'error');
+        }
       }
       closeCleanup();
 
@@ -745,6 +889,11 @@
   }
 
   // ===== Event Handlers =====
+  workspaceSelect.addEventListener('change', () => {
+    state.activeWorkspace = workspaceSelect.value;
+    autoSelectFirst();
+  });
+
   categoryNav.addEventListener('click', (e) => {
     const btn = e.target.closest('.category-btn');
     if (!btn) return;
@@ -767,9 +916,20 @@
     openFile(item.dataset.id);
   });
 
-  searchInput.addEventListener('input', debounce((e) => {
-    state.searchQuery = e.target.value;
+  searchInput.addEventListener('input', debounce(async (e) => {
+    const q = e.target.value;
+    state.searchQuery = q;
+    state.contentMatchIds = new Set();
     updateView();
+    if (!q.trim()) return;
+    try {
+      const result = await searchContent(q);
+      if (state.searchQuery !== q) return; // a newer query is in flight
+      state.contentMatchIds = new Set((result.hits || []).map(h => h.id));
+      updateView();
+    } catch (err) {
+      // Content search is best-effort; name/path filtering still applies.
+    }
   }, 200));
 
   rescanBtn.addEventListener('click', handleRescan);
@@ -799,6 +959,52 @@
     }
   });
 
+  // ===== Live Updates =====
+  // The server pushes add/modify/delete events while it watches the scanned
+  // directories, "scan" events while a full scan runs, and "rescan" when the
+  // whole list should be reloaded.
+  function watchEvents() {
+    if (!window.EventSource) return;
+    const source = new EventSource('/api/events');
+
+    source.addEventListener('add', (e) => applyFileEvent(JSON.parse(e.data)));
+    source.addEventListener('modify', (e) => applyFileEvent(JSON.parse(e.data)));
+    source.addEventListener('delete', (e) => applyFileEvent(JSON.parse(e.data)));
+    source.addEventListener('rescan', debounce(loadFiles, 300));
+    source.addEventListener('scan', (e) => {
+      const progress = JSON.parse(e.data).progress;
+      if (rescanBtn.disabled && !progress.done) {
+        rescanBtn.innerHTML = '<span class="spinner"></span> Scanning... ' + progress.files + ' found';
+      }
+    });
+  }
+
+  function applyFileEvent(ev) {
+    const idx = state.files.findIndex(f => f.id === ev.id);
+    if (ev.type === 'delete') {
+      if (idx !== -1) state.files.splice(idx, 1);
+    } else if (idx !== -1) {
+      state.files[idx] = ev.file;
+    } else {
+      state.files.push(ev.file);
+    }
+    scanInfo.textContent = state.files.length + ' file' + (state.files.length !== 1 ? 's' : '') + ' found';
+    updateView();
+
+    if (ev.id !== state.activeFileId) return;
+    const modified = editorTextarea.value !== state.originalContent;
+    if (ev.type === 'delete') {
+      editorStatus.textContent = 'Deleted on disk';
+      editorStatus.className = 'editor-status error';
+    } else if (ev.type === 'modify' && modified) {
+      // Keep the user's edits; saving will report the conflict.
+      editorStatus.textContent = 'Changed on disk';
+      editorStatus.className = 'editor-status modified';
+    } else if (ev.type === 'modify') {
+      openFile(ev.id);
+    }
+  }
+
   // ===== Utilities =====
   function debounce(fn, ms) {
     let timer;
@@ -848,4 +1054,5 @@
 
   // ===== Init =====
   loadFiles();
+  watchEvents();
 })();